@@ -0,0 +1,228 @@
+package echopen
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+)
+
+// Get returns the value bound to the context under key as a T.
+// Bound structs may be requested as either the struct type or a pointer to it.
+// Returns ErrContextValueType if T does not match the type declared for the route, or the bound value,
+// and ErrContextValueMissing if nothing was bound (e.g. an optional parameter or body was omitted).
+func Get[T any](c echo.Context, key string) (T, error) {
+	var zero T
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	// Check against the declared type first, so mismatches are reported even when nothing was bound
+	if r, ok := c.Get(routeContextKey).(*RouteWrapper); ok {
+		if declared := r.declaredType(key); declared != nil && !contextTypeMatches(typ, declared) {
+			return zero, fmt.Errorf("%w: %s declared as %s, not %s", ErrContextValueType, key, declared, typ)
+		}
+	}
+
+	v := c.Get(key)
+	if v == nil {
+		return zero, fmt.Errorf("%w: %s", ErrContextValueMissing, key)
+	} else if t, ok := v.(T); ok {
+		return t, nil
+	}
+
+	if rv, ok := convertContextValue(reflect.ValueOf(v), typ); ok {
+		return rv.Interface().(T), nil
+	}
+	return zero, fmt.Errorf("%w: %s is %T, not %s", ErrContextValueType, key, v, typ)
+}
+
+// Body returns the bound request body
+func Body[T any](c echo.Context) (T, error) {
+	return Get[T](c, "body")
+}
+
+// Query returns the bound query struct
+func Query[T any](c echo.Context) (T, error) {
+	return Get[T](c, "query")
+}
+
+// QueryParam returns a single query parameter, when the route has no query struct
+func QueryParam[T any](c echo.Context, name string) (T, error) {
+	return Get[T](c, fmt.Sprintf("query.%s", name))
+}
+
+// Form returns the bound form struct
+func Form[T any](c echo.Context) (T, error) {
+	return Get[T](c, "form")
+}
+
+// PathParam returns a single path parameter
+func PathParam[T any](c echo.Context, name string) (T, error) {
+	return Get[T](c, fmt.Sprintf("path.%s", name))
+}
+
+// Header returns a single header parameter, with the name canonicalised in the same way as the parameter
+func Header[T any](c echo.Context, name string) (T, error) {
+	return Get[T](c, fmt.Sprintf("header.%s", http.CanonicalHeaderKey(name)))
+}
+
+// Cookie returns a single cookie parameter
+func Cookie[T any](c echo.Context, name string) (T, error) {
+	return Get[T](c, fmt.Sprintf("cookie.%s", name))
+}
+
+// Principal returns the principal resolved by the verifier for a security scheme
+func Principal[T any](c echo.Context, scheme string) (T, error) {
+	return Get[T](c, fmt.Sprintf("security.%s.principal", scheme))
+}
+
+// WithContextType declares the type a handler will retrieve under key with the accessors above.
+// Registration panics if it does not match the type declared by the rest of the route config, regardless of order.
+func WithContextType[T any](key string) RouteConfigFunc {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	return func(rw *RouteWrapper) *RouteWrapper {
+		if rw.ContextTypes == nil {
+			rw.ContextTypes = map[string]reflect.Type{}
+		}
+		rw.ContextTypes[key] = typ
+		return rw
+	}
+}
+
+// checkContextTypes panics if any type given by WithContextType does not match the declared type
+func (r *RouteWrapper) checkContextTypes() {
+	keys := make([]string, 0, len(r.ContextTypes))
+	for key := range r.ContextTypes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		typ := r.ContextTypes[key]
+		declared := r.declaredType(key)
+		if declared == nil {
+			panic(fmt.Errorf("echopen: no type declared for %s on %s %s", key, r.Method, r.Path))
+		} else if !contextTypeMatches(typ, declared) {
+			panic(fmt.Errorf("echopen: %s declared as %s on %s %s, not %s", key, declared, r.Method, r.Path, typ))
+		}
+	}
+}
+
+// declaredType returns the Go type bound to the context under key, or nil if not known.
+// Principals and bodies declared only by schema have no known type.
+func (r *RouteWrapper) declaredType(key string) reflect.Type {
+	sourceType := func(s *v310.Schema) reflect.Type {
+		if s == nil {
+			return nil
+		}
+		return s.SourceType
+	}
+
+	switch key {
+	case "body":
+		// Only report a type if every content type binds the same one
+		var typ reflect.Type
+		for _, s := range r.RequestBodySchema {
+			if s.SourceType == nil || (typ != nil && typ != s.SourceType) {
+				return nil
+			}
+			typ = s.SourceType
+		}
+		return typ
+	case "query":
+		return sourceType(r.QuerySchema)
+	case "form":
+		return sourceType(r.FormSchema)
+	case "path":
+		return sourceType(r.PathSchema)
+	case "header":
+		return sourceType(r.HeaderSchema)
+	case "cookie":
+		return sourceType(r.CookieSchema)
+	}
+
+	in, name, _ := strings.Cut(key, ".")
+	for _, param := range r.parameters() {
+		if string(param.In) == in && param.Name == name {
+			return sourceType(param.Schema)
+		}
+	}
+	return nil
+}
+
+// contextTypeMatches allows bound structs to be requested by pointer or by value
+func contextTypeMatches(typ reflect.Type, declared reflect.Type) bool {
+	return typ == declared || typ == reflect.PointerTo(declared)
+}
+
+// convertContextValue converts a bound value to the requested type where the stored representation differs.
+// Structs are stored as pointers, parameters parsed from strings may use a different numeric type, and
+// array parameters are stored as []interface{}.
+func convertContextValue(v reflect.Value, typ reflect.Type) (reflect.Value, bool) {
+	if !v.IsValid() {
+		return v, false
+	} else if v.Type().AssignableTo(typ) {
+		return v, true
+	}
+
+	switch {
+	case v.Kind() == reflect.Interface:
+		return convertContextValue(v.Elem(), typ)
+	case v.Kind() == reflect.Pointer && !v.IsNil() && v.Type().Elem() == typ:
+		return v.Elem(), true
+	case isNumericKind(v.Kind()) && isNumericKind(typ.Kind()):
+		return convertNumber(v, typ)
+	case v.Kind() == reflect.Slice && typ.Kind() == reflect.Slice:
+		out := reflect.MakeSlice(typ, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, ok := convertContextValue(v.Index(i), typ.Elem())
+			if !ok {
+				return v, false
+			}
+			out.Index(i).Set(item)
+		}
+		return out, true
+	}
+	return v, false
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// convertNumber converts between numeric types, failing if the value is out of range for the requested type,
+// or is a float with a fractional part being converted to an integer type.
+func convertNumber(v reflect.Value, typ reflect.Type) (reflect.Value, bool) {
+	target := reflect.Zero(typ)
+
+	switch {
+	case v.CanInt():
+		i := v.Int()
+		if (target.CanInt() && target.OverflowInt(i)) || (target.CanUint() && (i < 0 || target.OverflowUint(uint64(i)))) {
+			return v, false
+		}
+	case v.CanUint():
+		u := v.Uint()
+		if (target.CanInt() && (u > math.MaxInt64 || target.OverflowInt(int64(u)))) || (target.CanUint() && target.OverflowUint(u)) {
+			return v, false
+		}
+	default:
+		f := v.Float()
+		if target.CanFloat() {
+			if target.OverflowFloat(f) {
+				return v, false
+			}
+		} else if math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f) {
+			return v, false
+		} else if target.CanInt() && (f < math.MinInt64 || f >= math.MaxInt64 || target.OverflowInt(int64(f))) {
+			return v, false
+		} else if target.CanUint() && (f < 0 || f >= math.MaxUint64 || target.OverflowUint(uint64(f))) {
+			return v, false
+		}
+	}
+	return v.Convert(typ), true
+}
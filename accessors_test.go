@@ -0,0 +1,163 @@
+package echopen_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/richjyoung/echopen"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessors(t *testing.T) {
+	type User struct {
+		Name string
+	}
+	type Body struct {
+		Name string `json:"name"`
+	}
+	type Query struct {
+		Limit int `query:"limit"`
+	}
+
+	api := echopen.New(
+		"Test",
+		"1.0.0",
+		echopen.WithSecurityVerifier("api_key", func(c echo.Context, value string, scopes []string) (interface{}, error) {
+			return &User{Name: value}, nil
+		}),
+	)
+	api.Spec.GetComponents().AddSecurityScheme("api_key", &v310.SecurityScheme{
+		Type: v310.APIKeySecuritySchemeType,
+		In:   "header",
+		Name: "X-API-Key",
+	})
+
+	api.POST(
+		"/pets/:id",
+		func(c echo.Context) error {
+			body, err := echopen.Body[*Body](c)
+			assert.NoError(t, err)
+			assert.Equal(t, "Rex", body.Name)
+
+			byValue, err := echopen.Body[Body](c)
+			assert.NoError(t, err)
+			assert.Equal(t, "Rex", byValue.Name)
+
+			query, err := echopen.Query[*Query](c)
+			assert.NoError(t, err)
+			assert.Equal(t, 10, query.Limit)
+
+			id, err := echopen.PathParam[uint](c, "id")
+			assert.NoError(t, err)
+			assert.Equal(t, uint(42), id)
+
+			tags, err := echopen.Header[[]string](c, "x-tags")
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"a", "b"}, tags)
+
+			user, err := echopen.Principal[*User](c, "api_key")
+			assert.NoError(t, err)
+			assert.Equal(t, "key", user.Name)
+
+			// Type mismatches with the declared type are errors rather than panics
+			_, err = echopen.Body[*Query](c)
+			assert.True(t, errors.Is(err, echopen.ErrContextValueType))
+			_, err = echopen.PathParam[string](c, "id")
+			assert.True(t, errors.Is(err, echopen.ErrContextValueType))
+			_, err = echopen.Principal[string](c, "api_key")
+			assert.True(t, errors.Is(err, echopen.ErrContextValueType))
+
+			// Optional values which were not sent
+			_, err = echopen.Cookie[string](c, "session")
+			assert.True(t, errors.Is(err, echopen.ErrContextValueMissing))
+			_, err = echopen.Form[*Body](c)
+			assert.True(t, errors.Is(err, echopen.ErrContextValueMissing))
+
+			return c.NoContent(204)
+		},
+		echopen.WithContextType[*Body]("body"),
+		echopen.WithRequestBodyStruct(echo.MIMEApplicationJSON, "Pet", Body{}),
+		echopen.WithQueryStruct(Query{}),
+		echopen.WithPathParameter("id", "Pet ID", uint(0)),
+		echopen.WithHeaderParameter("X-Tags", "Tags", []string{}),
+		echopen.WithCookieParameter("session", "Session", ""),
+		echopen.WithSecurityRequirement("api_key", []string{}),
+		echopen.WithContextType[uint]("path.id"),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/pets/42?limit=10", strings.NewReader(`{"name":"Rex"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-API-Key", "key")
+	req.Header.Add("X-Tags", "a")
+	req.Header.Add("X-Tags", "b")
+	res := httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 204, res.Result().StatusCode)
+}
+
+func TestWithContextType(t *testing.T) {
+	type Body struct {
+		Name string `json:"name"`
+	}
+
+	api := echopen.New("Test", "1.0.0")
+	handler := func(c echo.Context) error { return c.NoContent(204) }
+
+	assert.NotPanics(t, func() {
+		api.POST("/valid", handler, echopen.WithRequestBodyStruct(echo.MIMEApplicationJSON, "", Body{}), echopen.WithContextType[Body]("body"))
+	})
+	assert.PanicsWithError(t, "echopen: body declared as echopen_test.Body on POST /mismatch, not string", func() {
+		api.POST("/mismatch", handler, echopen.WithRequestBodyStruct(echo.MIMEApplicationJSON, "", Body{}), echopen.WithContextType[string]("body"))
+	})
+	assert.PanicsWithError(t, "echopen: no type declared for query on POST /undeclared", func() {
+		api.POST("/undeclared", handler, echopen.WithContextType[*Body]("query"))
+	})
+}
+
+func TestGetNumericConversion(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+	c := api.Engine.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	c.Set("small", int64(-128))
+	c.Set("large", 300)
+	c.Set("negative", -1)
+	c.Set("whole", 2.0)
+	c.Set("fraction", 1.5)
+	c.Set("huge", 1e39)
+	c.Set("items", []interface{}{1, 300})
+
+	small, err := echopen.Get[int8](c, "small")
+	assert.NoError(t, err)
+	assert.Equal(t, int8(-128), small)
+
+	whole, err := echopen.Get[uint](c, "whole")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), whole)
+
+	large, err := echopen.Get[uint16](c, "large")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(300), large)
+
+	fraction, err := echopen.Get[float32](c, "fraction")
+	assert.NoError(t, err)
+	assert.Equal(t, float32(1.5), fraction)
+
+	// Values which would be changed by the conversion are errors
+	_, err = echopen.Get[uint8](c, "large")
+	assert.True(t, errors.Is(err, echopen.ErrContextValueType))
+	_, err = echopen.Get[uint](c, "negative")
+	assert.True(t, errors.Is(err, echopen.ErrContextValueType))
+	_, err = echopen.Get[int](c, "fraction")
+	assert.True(t, errors.Is(err, echopen.ErrContextValueType))
+	_, err = echopen.Get[float32](c, "huge")
+	assert.True(t, errors.Is(err, echopen.ErrContextValueType))
+	_, err = echopen.Get[int64](c, "huge")
+	assert.True(t, errors.Is(err, echopen.ErrContextValueType))
+	_, err = echopen.Get[[]uint8](c, "items")
+	assert.True(t, errors.Is(err, echopen.ErrContextValueType))
+}
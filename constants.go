@@ -6,4 +6,16 @@ var (
 	ErrRequiredParameterMissing   = fmt.Errorf("echopen: required parameter missing")
 	ErrSecurityRequirementsNotMet = fmt.Errorf("echopen: at least one required security scheme must be provided")
 	ErrContentTypeNotSupported    = fmt.Errorf("echopen: request did not match defined content types")
+	ErrSecurityCredentialsInvalid = fmt.Errorf("echopen: security credentials invalid")
+	ErrSecurityAccessDenied       = fmt.Errorf("echopen: security credentials do not permit access")
+	ErrInsufficientScope          = fmt.Errorf("echopen: security credentials do not grant the required scopes")
+	ErrJWTMalformed               = fmt.Errorf("echopen: malformed JWT")
+	ErrJWTSignatureInvalid        = fmt.Errorf("echopen: JWT signature invalid")
+	ErrJWTClaimsInvalid           = fmt.Errorf("echopen: JWT claims invalid")
+	ErrContextValueMissing        = fmt.Errorf("echopen: no value bound to context")
+	ErrContextValueType           = fmt.Errorf("echopen: context value does not match requested type")
+	ErrNotAcceptable              = fmt.Errorf("echopen: no acceptable media type declared for response")
+	ErrResponseInvalid            = fmt.Errorf("echopen: response does not match the specification")
+	ErrNotImplemented             = fmt.Errorf("echopen: no handler for operation")
+	ErrHandlersMissing            = fmt.Errorf("echopen: operations without handlers")
 )
@@ -37,6 +37,22 @@ type RequestBody struct {
 }
 
 func main() {
+	api := newAPI()
+
+	// Serve the generated schema
+	api.ServeJSONSpec("/openapi.json")
+	api.ServeYAMLSpec("/openapi.yml")
+	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
+
+	// Write the full generated spec
+	api.WriteYAMLSpec("openapi_out.yml")
+
+	// Start the server
+	api.Start("localhost:3000")
+}
+
+// newAPI builds the example API and its spec, without serving anything
+func newAPI() *echopen.APIWrapper {
 	// Create a new echOpen wrapper
 	api := echopen.New(
 		"Basic Example",
@@ -80,16 +96,7 @@ func main() {
 		echopen.WithResponseDescription("200", "Successful response"),
 	)
 
-	// Serve the generated schema
-	api.ServeJSONSpec("/openapi.json")
-	api.ServeYAMLSpec("/openapi.yml")
-	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
-
-	// Write the full generated spec
-	api.WriteYAMLSpec("openapi_out.yml")
-
-	// Start the server
-	api.Start("localhost:3000")
+	return api
 }
 
 func hello(c echo.Context) error {
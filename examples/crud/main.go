@@ -36,6 +36,21 @@ var (
 )
 
 func main() {
+	api := newAPI()
+
+	// Serve the generated schema
+	api.ServeYAMLSpec("/openapi.yml")
+	api.ServeRapidoc("/", "/openapi.yml")
+
+	// Write the full generated spec
+	api.WriteYAMLSpec("openapi_out.yml")
+
+	// Start the server
+	api.Start("localhost:3000")
+}
+
+// newAPI builds the example API and its spec, without serving anything
+func newAPI() *echopen.APIWrapper {
 	api := echopen.New(
 		"Responses",
 		"1.0.0",
@@ -100,15 +115,7 @@ func main() {
 		echopen.WithResponseRef("default", "UnexpectedErrorResponse"),
 	)
 
-	// Serve the generated schema
-	api.ServeYAMLSpec("/openapi.yml")
-	api.ServeRapidoc("/", "/openapi.yml")
-
-	// Write the full generated spec
-	api.WriteYAMLSpec("openapi_out.yml")
-
-	// Start the server
-	api.Start("localhost:3000")
+	return api
 }
 
 func getTodos(c echo.Context) error {
@@ -11,6 +11,21 @@ import (
 )
 
 func main() {
+	api := newAPI()
+
+	// Serve the generated schema
+	api.ServeYAMLSpec("/openapi.yml")
+	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
+
+	// Write the full generated spec
+	api.WriteYAMLSpec("openapi_out.yml")
+
+	// Start the server
+	api.Start("localhost:3000")
+}
+
+// newAPI builds the example API and its spec, without serving anything
+func newAPI() *echopen.APIWrapper {
 	// Create a new echOpen wrapper
 	api := echopen.New(
 		"Hello World",
@@ -31,15 +46,7 @@ func main() {
 		echopen.WithResponseDescription("default", "Unexpected error"),
 	)
 
-	// Serve the generated schema
-	api.ServeYAMLSpec("/openapi.yml")
-	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
-
-	// Write the full generated spec
-	api.WriteYAMLSpec("openapi_out.yml")
-
-	// Start the server
-	api.Start("localhost:3000")
+	return api
 }
 
 func hello(c echo.Context) error {
@@ -5,12 +5,7 @@ import (
 )
 
 func main() {
-	// Create a new echOpen wrapper
-	api := echopen.New(
-		"Minimal",
-		"1.0.0",
-		echopen.WithSpecDescription("Minimal example to get the server running."),
-	)
+	api := newAPI()
 
 	// Serve the generated schema
 	api.ServeYAMLSpec("/openapi.yml")
@@ -22,3 +17,15 @@ func main() {
 	// Start the server
 	api.Start("localhost:3000")
 }
+
+// newAPI builds the example API and its spec, without serving anything
+func newAPI() *echopen.APIWrapper {
+	// Create a new echOpen wrapper
+	api := echopen.New(
+		"Minimal",
+		"1.0.0",
+		echopen.WithSpecDescription("Minimal example to get the server running."),
+	)
+
+	return api
+}
@@ -34,6 +34,21 @@ type ErrorResponseBody struct {
 }
 
 func main() {
+	api := newAPI()
+
+	// Serve the generated schema
+	api.ServeYAMLSpec("/openapi.yml")
+	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
+
+	// Write the full generated spec
+	api.WriteYAMLSpec("openapi_out.yml")
+
+	// Start the server
+	api.Start("localhost:3000")
+}
+
+// newAPI builds the example API and its spec, without serving anything
+func newAPI() *echopen.APIWrapper {
 	// Create a new echOpen wrapper
 	api := echopen.New(
 		"Parameters",
@@ -55,15 +70,7 @@ func main() {
 		echopen.WithResponseStruct(fmt.Sprint(http.StatusNotFound), "Not found", ErrorResponseBody{}),
 	)
 
-	// Serve the generated schema
-	api.ServeYAMLSpec("/openapi.yml")
-	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
-
-	// Write the full generated spec
-	api.WriteYAMLSpec("openapi_out.yml")
-
-	// Start the server
-	api.Start("localhost:3000")
+	return api
 }
 
 func getParamsByID(c echo.Context) error {
@@ -10,6 +10,21 @@ import (
 )
 
 func main() {
+	api := newAPI()
+
+	// Serve the generated schema
+	api.ServeYAMLSpec("/openapi.yml")
+	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
+
+	// Write the full generated spec
+	api.WriteYAMLSpec("openapi_out.yml")
+
+	// Start the server
+	api.Start("localhost:3000")
+}
+
+// newAPI builds the example API and its spec, without serving anything
+func newAPI() *echopen.APIWrapper {
 	// Create a new echOpen wrapper
 	api := echopen.New(
 		"Swagger Petstore",
@@ -84,15 +99,7 @@ Sed tempus felis lobortis leo pulvinar rutrum. Nam mattis velit nisl, eu condime
 		echopen.WithResponseStruct("default", "unexpected error", Error{}),
 	)
 
-	// Serve the generated schema
-	api.ServeYAMLSpec("/openapi.yml")
-	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
-
-	// Write the full generated spec
-	api.WriteYAMLSpec("openapi_out.yml")
-
-	// Start the server
-	api.Start("localhost:3000")
+	return api
 }
 
 func findPets(c echo.Context) error {
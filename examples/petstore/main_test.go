@@ -0,0 +1,11 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/richjyoung/echopen/internal/spectest"
+)
+
+func TestSpecCopy(t *testing.T) {
+	spectest.AssertDeepCopy(t, newAPI().Spec)
+}
@@ -12,6 +12,21 @@ Swagger at [http://swagger.io](http://swagger.io) or on [irc.freenode.net, #swag
 this sample, you can use the api key "special-key" to test the authorization filters.`
 
 func main() {
+	api := newAPI()
+
+	// Serve the generated schema
+	api.ServeYAMLSpec("/openapi.yml")
+	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
+
+	// Write the full generated spec
+	api.WriteYAMLSpec("openapi_out.yml")
+
+	// Start the server
+	api.Start("localhost:3000")
+}
+
+// newAPI builds the example API and its spec, without serving anything
+func newAPI() *echopen.APIWrapper {
 	// Create a new echOpen wrapper
 	api := echopen.New(
 		"Swagger Petstore",
@@ -211,7 +226,7 @@ func main() {
 		echopen.WithOperationID("getOrderById"),
 		echopen.WithSummary("Find purchase order by ID"),
 		echopen.WithDescription("For valid response try integer IDs with value >= 1 and <= 10. Other values will generated exceptions"),
-		echopen.WithPathParameter("orderId", "ID of order that needs to be fetched", int64(0)),
+		echopen.WithPathStruct(GetOrderByIDPath{}),
 		echopen.WithResponseStruct("200", "successful operation", Order{}),
 		echopen.WithResponseDescription("400", "Invalid ID supplied"),
 		echopen.WithResponseDescription("404", "Order not found"),
@@ -223,7 +238,7 @@ func main() {
 		echopen.WithOperationID("deleteOrder"),
 		echopen.WithSummary("Delete purchase order by ID"),
 		echopen.WithDescription("For valid response try integer IDs with positive integer value. Negative or non-integer values will generate API errors"),
-		echopen.WithPathParameter("orderId", "ID of the order that needs to be deleted", int64(0)),
+		echopen.WithPathStruct(DeleteOrderByIDPath{}),
 		echopen.WithResponseDescription("400", "Invalid ID supplied"),
 		echopen.WithResponseDescription("404", "Order not found"),
 	)
@@ -308,15 +323,7 @@ func main() {
 		echopen.WithResponseDescription("404", "User not found"),
 	)
 
-	// Serve the generated schema
-	api.ServeYAMLSpec("/openapi.yml")
-	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
-
-	// Write the full generated spec
-	api.WriteYAMLSpec("openapi_out.yml")
-
-	// Start the server
-	api.Start("localhost:3000")
+	return api
 }
 
 func noop(c echo.Context) error {
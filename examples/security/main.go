@@ -14,6 +14,21 @@ type ErrorResponseBody struct {
 }
 
 func main() {
+	api := newAPI()
+
+	// Serve the generated schema
+	api.ServeYAMLSpec("/openapi.yml")
+	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
+
+	// Write the full generated spec
+	api.WriteYAMLSpec("openapi_out.yml")
+
+	// Start the server
+	api.Start("localhost:3000")
+}
+
+// newAPI builds the example API and its spec, without serving anything
+func newAPI() *echopen.APIWrapper {
 	// Create a new echOpen wrapper
 	api := echopen.New(
 		"Hello World",
@@ -30,6 +45,16 @@ func main() {
 		Name: "X-API-Key",
 	})
 
+	api.Spec.GetComponents().AddSecurityScheme("bearer", &v310.SecurityScheme{
+		Type:   v310.HTTPSecuritySchemeType,
+		Scheme: "bearer",
+	})
+
+	api.Spec.GetComponents().AddSecurityScheme("basic", &v310.SecurityScheme{
+		Type:   v310.HTTPSecuritySchemeType,
+		Scheme: "basic",
+	})
+
 	// Optional security route
 	api.GET(
 		"/hello",
@@ -49,15 +74,25 @@ func main() {
 		echopen.WithResponseRef("default", "ErrorResponse"),
 	)
 
-	// Serve the generated schema
-	api.ServeYAMLSpec("/openapi.yml")
-	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
+	// Bearer token route
+	api.GET(
+		"/hello_bearer",
+		helloBearer,
+		echopen.WithSecurityRequirement("bearer", []string{}),
+		echopen.WithResponseDescription(fmt.Sprint(http.StatusOK), "Successful response"),
+		echopen.WithResponseRef("default", "ErrorResponse"),
+	)
 
-	// Write the full generated spec
-	api.WriteYAMLSpec("openapi_out.yml")
+	// Basic auth route
+	api.GET(
+		"/hello_basic",
+		helloBasic,
+		echopen.WithSecurityRequirement("basic", []string{}),
+		echopen.WithResponseDescription(fmt.Sprint(http.StatusOK), "Successful response"),
+		echopen.WithResponseRef("default", "ErrorResponse"),
+	)
 
-	// Start the server
-	api.Start("localhost:3000")
+	return api
 }
 
 func hello(c echo.Context) error {
@@ -73,3 +108,16 @@ func helloSecure(c echo.Context) error {
 		"scopes": c.Get("security.api_key.scopes"),
 	})
 }
+
+func helloBearer(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"token": c.Get("security.bearer"),
+	})
+}
+
+func helloBasic(c echo.Context) error {
+	creds := c.Get("security.basic").(echopen.BasicCredentials)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"username": creds.Username,
+	})
+}
@@ -10,6 +10,23 @@ import (
 )
 
 func main() {
+	api := newAPI()
+
+	// Serve the generated schema
+	api.ServeYAMLSpec("/openapi.yml", echopen.ExcludeTags("hidden"))
+	api.ServeYAMLSpec("/openapi_hidden_only.yml", echopen.IncludeTags("hidden"))
+	api.ServeYAMLSpec("/openapi_all.yml")
+	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
+
+	// Write the full generated spec
+	api.WriteYAMLSpec("openapi_out.yml")
+
+	// Start the server
+	api.Start("localhost:3000")
+}
+
+// newAPI builds the example API and its spec, without serving anything
+func newAPI() *echopen.APIWrapper {
 	// Create a new echOpen wrapper
 	api := echopen.New(
 		"Tags Example",
@@ -36,17 +53,7 @@ func main() {
 		echopen.WithResponseStruct(fmt.Sprint(http.StatusOK), "Default response", ""),
 	)
 
-	// Serve the generated schema
-	api.ServeYAMLSpec("/openapi.yml", echopen.ExcludeTags("hidden"))
-	api.ServeYAMLSpec("/openapi_hidden_only.yml", echopen.IncludeTags("hidden"))
-	api.ServeYAMLSpec("/openapi_all.yml")
-	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
-
-	// Write the full generated spec
-	api.WriteYAMLSpec("openapi_out.yml")
-
-	// Start the server
-	api.Start("localhost:3000")
+	return api
 }
 
 func hello(c echo.Context) error {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -28,6 +29,21 @@ type Error struct {
 }
 
 func main() {
+	api := newAPI()
+
+	// Serve the generated schema
+	api.ServeYAMLSpec("/openapi.yml")
+	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
+
+	// Write the full generated spec
+	api.WriteYAMLSpec("openapi_out.yml")
+
+	// Start the server
+	api.Start("localhost:3000")
+}
+
+// newAPI builds the example API and its spec, without serving anything
+func newAPI() *echopen.APIWrapper {
 	// Create a new echOpen wrapper
 	api := echopen.New(
 		"Validation",
@@ -48,15 +64,7 @@ func main() {
 		echopen.WithResponseStruct("default", "Error response", Error{}),
 	)
 
-	// Serve the generated schema
-	api.ServeYAMLSpec("/openapi.yml")
-	api.ServeSwaggerUI("/", "/openapi.yml", "5.10.3")
-
-	// Write the full generated spec
-	api.WriteYAMLSpec("openapi_out.yml")
-
-	// Start the server
-	api.Start("localhost:3000")
+	return api
 }
 
 func validate(c echo.Context) error {
@@ -70,13 +78,17 @@ func validate(c echo.Context) error {
 func onError(err error, c echo.Context) {
 	var err2 error
 
-	if ve, ok := err.(validator.ValidationErrors); ok {
+	// Validation errors are wrapped in *echopen.ValidationError, so must be matched with errors.As
+	var ve validator.ValidationErrors
+	var he *echo.HTTPError
+
+	if errors.As(err, &ve) {
 		// Validation error - send a 400 with the first error
 		err2 = c.JSON(http.StatusBadRequest, Error{
 			Code:    "bad_request",
 			Message: ve[0].Error(),
 		})
-	} else if he, ok := err.(*echo.HTTPError); ok {
+	} else if errors.As(err, &he) {
 		// Echo builtin HTTP error - send the code with the provided message
 		err2 = c.JSON(he.Code, struct {
 			Message interface{} `json:"message,omitempty"`
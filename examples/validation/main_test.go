@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/richjyoung/echopen/internal/spectest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpecCopy(t *testing.T) {
+	spectest.AssertDeepCopy(t, newAPI().Spec)
+}
+
+func TestErrorHandler(t *testing.T) {
+	api := newAPI()
+
+	req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"string_len":"too long for the rule","num_range":5}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	res := httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Contains(t, res.Body.String(), `"code":"bad_request"`)
+}
@@ -20,6 +20,8 @@ type GroupWrapper struct {
 
 // Create a new sub-group with prefix and optional group-specific configuration
 func (g *GroupWrapper) Group(prefix string, config ...GroupConfigFunc) *GroupWrapper {
+	defer g.API.SpecChanged()
+
 	wrapper := &GroupWrapper{
 		Prefix:       prefix,
 		API:          g.API,
@@ -39,6 +41,11 @@ func (g *GroupWrapper) Group(prefix string, config ...GroupConfigFunc) *GroupWra
 
 // Add a route to the group
 func (g *GroupWrapper) Add(method string, path string, handler echo.HandlerFunc, config ...RouteConfigFunc) *RouteWrapper {
+	if g.API.Frozen() {
+		panic(fmt.Sprintf("echopen: cannot add %s %s after the spec is frozen", method, path))
+	}
+	defer g.API.SpecChanged()
+
 	// Construct a new operation for this path and method
 	op := &v310.Operation{}
 
@@ -87,6 +94,8 @@ func (g *GroupWrapper) Add(method string, path string, handler echo.HandlerFunc,
 	wrapper := &RouteWrapper{
 		API:               g.API,
 		Group:             g,
+		Method:            strings.ToUpper(method),
+		Path:              fullPath,
 		Operation:         op,
 		PathItem:          pathItem,
 		Handler:           handler,
@@ -98,9 +107,7 @@ func (g *GroupWrapper) Add(method string, path string, handler echo.HandlerFunc,
 	for parentGroup != nil {
 		wrapper = WithTags(parentGroup.Tags...)(wrapper)
 		for _, req := range parentGroup.SecurityRequirements {
-			for name, scopes := range *req {
-				wrapper = WithSecurityRequirement(name, scopes)(wrapper)
-			}
+			wrapper = WithCombinedSecurityRequirement(req)(wrapper)
 		}
 		parentGroup = parentGroup.GroupWrapper
 	}
@@ -110,11 +117,20 @@ func (g *GroupWrapper) Add(method string, path string, handler echo.HandlerFunc,
 		wrapper = configFunc(wrapper)
 	}
 
+	// Check the types expected by the handler against those bound
+	wrapper.checkContextTypes()
+
+	// Document validation failures
+	wrapper.addProblemDetailsResponse()
+
 	// Add validation middleware to the start of the chain
 	middlewares := []echo.MiddlewareFunc{}
 	if !g.API.Config.DisableDefaultMiddleware {
 		middlewares = append(middlewares, wrapper.middleware())
 	}
+	if g.API.Config.ValidateResponses != ResponseValidationDisabled {
+		middlewares = append(middlewares, wrapper.responseValidationMiddleware())
+	}
 	middlewares = append(middlewares, wrapper.Middlewares...)
 
 	// Add the route in to the group (non-prefixed path)
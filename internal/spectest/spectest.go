@@ -0,0 +1,100 @@
+// Package spectest provides checks on generated specifications which are shared by the example tests.
+package spectest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+	"github.com/stretchr/testify/assert"
+)
+
+var reflectTypeType = reflect.TypeOf((*reflect.Type)(nil)).Elem()
+
+// AssertDeepCopy checks that Copy returns a specification equal to the original, which shares no memory with it
+// other than SourceType, and in which objects shared by the original are still shared.
+func AssertDeepCopy(t *testing.T, s *v310.Specification) {
+	t.Helper()
+
+	c := s.Copy()
+	if !assert.NotNil(t, c) {
+		return
+	}
+	assert.True(t, reflect.DeepEqual(s, c), "copy is not deeply equal to the original")
+
+	orig, err := json.Marshal(s)
+	assert.NoError(t, err)
+	copied, err := json.Marshal(c)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(orig), string(copied))
+
+	w := &walker{t: t, pairs: map[walkKey]uintptr{}}
+	w.walk(reflect.ValueOf(s), reflect.ValueOf(c), "spec")
+}
+
+type walkKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// walker compares an original and copied object graph in parallel
+type walker struct {
+	t     *testing.T
+	pairs map[walkKey]uintptr
+}
+
+func (w *walker) walk(a reflect.Value, b reflect.Value, path string) {
+	switch a.Kind() {
+	case reflect.Pointer:
+		if a.IsNil() {
+			return
+		}
+
+		key := walkKey{ptr: a.Pointer(), typ: a.Type()}
+		if prev, ok := w.pairs[key]; ok {
+			assert.Equal(w.t, prev, b.Pointer(), "%s is no longer shared", path)
+			return
+		}
+		w.pairs[key] = b.Pointer()
+
+		if a.Type().Elem().Size() > 0 {
+			assert.NotEqual(w.t, a.Pointer(), b.Pointer(), "%s shares memory with the original", path)
+		}
+		w.walk(a.Elem(), b.Elem(), path)
+
+	case reflect.Interface:
+		if a.IsNil() {
+			return
+		} else if a.Type() == reflectTypeType || a.Elem().Type().Implements(reflectTypeType) {
+			assert.True(w.t, a.Interface() == b.Interface(), "%s is not the same type", path)
+			return
+		}
+		w.walk(a.Elem(), b.Elem(), path)
+
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if a.Type().Field(i).IsExported() {
+				w.walk(a.Field(i), b.Field(i), fmt.Sprintf("%s.%s", path, a.Type().Field(i).Name))
+			}
+		}
+
+	case reflect.Slice:
+		if a.Len() > 0 {
+			assert.NotEqual(w.t, a.Pointer(), b.Pointer(), "%s shares memory with the original", path)
+		}
+		for i := 0; i < a.Len(); i++ {
+			w.walk(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i))
+		}
+
+	case reflect.Map:
+		if !a.IsNil() {
+			assert.NotEqual(w.t, a.Pointer(), b.Pointer(), "%s shares memory with the original", path)
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			w.walk(iter.Value(), b.MapIndex(iter.Key()), fmt.Sprintf("%s[%v]", path, iter.Key()))
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package echopen
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// JWTConfig controls validation of bearer tokens for HTTP bearer schemes with a BearerFormat of "JWT".
+type JWTConfig struct {
+	Keys     []*JWTKey
+	Audience string
+	Issuer   string
+	Leeway   time.Duration
+}
+
+// JWTKey is a single verification key. Key must be []byte for HS256, *rsa.PublicKey for RS256, or *ecdsa.PublicKey for ES256.
+type JWTKey struct {
+	ID        string
+	Algorithm string
+	Key       interface{}
+}
+
+// JWTClaims holds the registered claims of a validated token, along with the full claim set.
+type JWTClaims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	Scopes    []string
+	Raw       map[string]interface{}
+}
+
+// NewHMACKey creates an HS256 key from a shared secret.
+func NewHMACKey(id string, secret []byte) *JWTKey {
+	return &JWTKey{ID: id, Algorithm: "HS256", Key: secret}
+}
+
+// LoadJWKSFile reads a JSON Web Key Set from a local file.
+func LoadJWKSFile(path string) ([]*JWTKey, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(buf)
+}
+
+// ParseJWKS parses a JSON Web Key Set (RFC 7517) containing RSA, P-256 EC, or symmetric keys.
+// Keys of other types or curves are skipped, with an error returned only if no supported signing key remains.
+func ParseJWKS(buf []byte) ([]*JWTKey, error) {
+	set := struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}{}
+
+	if err := json.Unmarshal(buf, &set); err != nil {
+		return nil, err
+	}
+
+	keys := []*JWTKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key := &JWTKey{ID: k.Kid, Algorithm: k.Alg}
+
+		switch k.Kty {
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(k.N)
+			if err != nil {
+				return nil, fmt.Errorf("echopen: invalid JWK modulus for key %s: %w", k.Kid, err)
+			}
+			e, err := base64.RawURLEncoding.DecodeString(k.E)
+			if err != nil {
+				return nil, fmt.Errorf("echopen: invalid JWK exponent for key %s: %w", k.Kid, err)
+			}
+			key.Key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+			if key.Algorithm == "" {
+				key.Algorithm = "RS256"
+			}
+		case "EC":
+			if k.Crv != "P-256" {
+				continue
+			}
+			x, err := base64.RawURLEncoding.DecodeString(k.X)
+			if err != nil {
+				return nil, fmt.Errorf("echopen: invalid JWK x coordinate for key %s: %w", k.Kid, err)
+			}
+			y, err := base64.RawURLEncoding.DecodeString(k.Y)
+			if err != nil {
+				return nil, fmt.Errorf("echopen: invalid JWK y coordinate for key %s: %w", k.Kid, err)
+			}
+			key.Key = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+			if key.Algorithm == "" {
+				key.Algorithm = "ES256"
+			}
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil {
+				return nil, fmt.Errorf("echopen: invalid JWK secret for key %s: %w", k.Kid, err)
+			}
+			key.Key = secret
+			if key.Algorithm == "" {
+				key.Algorithm = "HS256"
+			}
+		default:
+			// Sets commonly include other key types, such as OKP, which are skipped
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("echopen: JWKS contains no supported signing keys")
+	}
+	return keys, nil
+}
+
+// LoadPEMKeyFile reads an RSA or EC public key (or certificate) from a local PEM file.
+func LoadPEMKeyFile(id string, path string) (*JWTKey, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePEMKey(id, buf)
+}
+
+// ParsePEMKey parses an RSA or EC public key from PEM encoded "PUBLIC KEY", "RSA PUBLIC KEY", or "CERTIFICATE" blocks.
+func ParsePEMKey(id string, buf []byte) (*JWTKey, error) {
+	block, _ := pem.Decode(buf)
+	if block == nil {
+		return nil, fmt.Errorf("echopen: no PEM block found")
+	}
+
+	var pub interface{}
+	var err error
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			pub = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("echopen: unsupported PEM block type %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return &JWTKey{ID: id, Algorithm: "RS256", Key: k}, nil
+	case *ecdsa.PublicKey:
+		return &JWTKey{ID: id, Algorithm: "ES256", Key: k}, nil
+	default:
+		return nil, fmt.Errorf("echopen: unsupported public key type %T", pub)
+	}
+}
+
+// Parse verifies the signature of a compact serialised JWT and validates the exp, nbf, aud, and iss claims.
+func (cfg *JWTConfig) Parse(token string) (*JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTMalformed
+	}
+
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+
+	// Find a key matching both the key ID (if given) and algorithm, to prevent algorithm substitution
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range cfg.Keys {
+		if key.Algorithm != header.Alg || (header.Kid != "" && key.ID != header.Kid) {
+			continue
+		}
+		if verifyJWTSignature(key, signed, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrJWTSignatureInvalid
+	}
+
+	raw := map[string]interface{}{}
+	if err := decodeJWTSegment(parts[1], &raw); err != nil {
+		return nil, err
+	}
+
+	claims := &JWTClaims{Raw: raw}
+	claims.Issuer, _ = raw["iss"].(string)
+	claims.Subject, _ = raw["sub"].(string)
+	claims.ExpiresAt = jwtNumericDate(raw["exp"])
+	claims.NotBefore = jwtNumericDate(raw["nbf"])
+	claims.IssuedAt = jwtNumericDate(raw["iat"])
+
+	switch aud := raw["aud"].(type) {
+	case string:
+		claims.Audience = []string{aud}
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				claims.Audience = append(claims.Audience, s)
+			}
+		}
+	}
+
+	// Scopes may be a space separated "scope" (RFC 8693) or a "scp" string/array
+	if scope, ok := raw["scope"].(string); ok {
+		claims.Scopes = strings.Fields(scope)
+	} else {
+		switch scp := raw["scp"].(type) {
+		case string:
+			claims.Scopes = strings.Fields(scp)
+		case []interface{}:
+			for _, s := range scp {
+				if str, ok := s.(string); ok {
+					claims.Scopes = append(claims.Scopes, str)
+				}
+			}
+		}
+	}
+
+	now := time.Now()
+	if !claims.ExpiresAt.IsZero() && now.After(claims.ExpiresAt.Add(cfg.Leeway)) {
+		return nil, fmt.Errorf("%w: token expired", ErrJWTClaimsInvalid)
+	}
+	if !claims.NotBefore.IsZero() && now.Add(cfg.Leeway).Before(claims.NotBefore) {
+		return nil, fmt.Errorf("%w: token not yet valid", ErrJWTClaimsInvalid)
+	}
+	if cfg.Issuer != "" && claims.Issuer != cfg.Issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrJWTClaimsInvalid)
+	}
+	if cfg.Audience != "" && !containsAll(claims.Audience, []string{cfg.Audience}) {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrJWTClaimsInvalid)
+	}
+
+	return claims, nil
+}
+
+func decodeJWTSegment(seg string, v interface{}) error {
+	buf, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return ErrJWTMalformed
+	}
+	if err := json.Unmarshal(buf, v); err != nil {
+		return ErrJWTMalformed
+	}
+	return nil
+}
+
+func jwtNumericDate(v interface{}) time.Time {
+	if f, ok := v.(float64); ok {
+		return time.Unix(int64(f), 0)
+	}
+	return time.Time{}
+}
+
+func verifyJWTSignature(key *JWTKey, signed []byte, sig []byte) bool {
+	switch key.Algorithm {
+	case "HS256":
+		secret, ok := key.Key.([]byte)
+		if !ok {
+			return false
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), sig)
+	case "RS256":
+		pub, ok := key.Key.(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+		hash := sha256.Sum256(signed)
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], sig) == nil
+	case "ES256":
+		pub, ok := key.Key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return false
+		}
+		hash := sha256.Sum256(signed)
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pub, hash[:], r, s)
+	default:
+		return false
+	}
+}
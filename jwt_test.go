@@ -0,0 +1,201 @@
+package echopen_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/richjyoung/echopen"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+	"github.com/stretchr/testify/assert"
+)
+
+func signJWT(t *testing.T, alg string, kid string, key interface{}, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, hash[:])
+		assert.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, hash[:])
+		assert.NoError(t, err)
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTParse(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	secret := []byte("secret")
+
+	// RSA key via JWKS file
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "rsa",
+			"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString([]byte{1, 0, 1}),
+		}},
+	})
+	jwksPath := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(jwksPath, jwks, 0600))
+	keys, err := echopen.LoadJWKSFile(jwksPath)
+	assert.NoError(t, err)
+
+	// EC key via PEM
+	der, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	ecJWTKey, err := echopen.ParsePEMKey("ec", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	assert.NoError(t, err)
+
+	cfg := &echopen.JWTConfig{
+		Keys:     append(keys, ecJWTKey, echopen.NewHMACKey("hmac", secret)),
+		Audience: "api",
+		Issuer:   "https://issuer.example.com",
+	}
+
+	now := time.Now().Unix()
+	valid := map[string]interface{}{
+		"iss":   "https://issuer.example.com",
+		"sub":   "user",
+		"aud":   []string{"api", "other"},
+		"exp":   now + 60,
+		"nbf":   now - 60,
+		"scope": "pets:read pets:write",
+	}
+
+	type tcd struct {
+		Name  string
+		Token string
+		Err   error
+	}
+
+	defs := []tcd{
+		{"rs256", signJWT(t, "RS256", "rsa", rsaKey, valid), nil},
+		{"es256", signJWT(t, "ES256", "ec", ecKey, valid), nil},
+		{"hs256", signJWT(t, "HS256", "hmac", secret, valid), nil},
+		{"wrong_key", signJWT(t, "HS256", "hmac", []byte("wrong"), valid), echopen.ErrJWTSignatureInvalid},
+		{"wrong_alg", signJWT(t, "HS256", "rsa", secret, valid), echopen.ErrJWTSignatureInvalid},
+		{"malformed", "abc.def", echopen.ErrJWTMalformed},
+		{"expired", signJWT(t, "HS256", "hmac", secret, map[string]interface{}{"iss": valid["iss"], "aud": "api", "exp": now - 60}), echopen.ErrJWTClaimsInvalid},
+		{"not_before", signJWT(t, "HS256", "hmac", secret, map[string]interface{}{"iss": valid["iss"], "aud": "api", "nbf": now + 60}), echopen.ErrJWTClaimsInvalid},
+		{"audience", signJWT(t, "HS256", "hmac", secret, map[string]interface{}{"iss": valid["iss"], "aud": "other"}), echopen.ErrJWTClaimsInvalid},
+		{"issuer", signJWT(t, "HS256", "hmac", secret, map[string]interface{}{"iss": "other", "aud": "api"}), echopen.ErrJWTClaimsInvalid},
+	}
+
+	for _, tc := range defs {
+		t.Run(tc.Name, func(t *testing.T) {
+			claims, err := cfg.Parse(tc.Token)
+			if tc.Err != nil {
+				assert.ErrorIs(t, err, tc.Err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "user", claims.Subject)
+				assert.Equal(t, []string{"pets:read", "pets:write"}, claims.Scopes)
+			}
+		})
+	}
+}
+
+func TestParseJWKSMixed(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+			{"kty": "EC", "kid": "p384", "crv": "P-384", "x": "AA", "y": "AA"},
+			{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AA", "e": "AQAB"},
+			{
+				"kty": "RSA",
+				"kid": "rsa",
+				"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString([]byte{1, 0, 1}),
+			},
+		},
+	})
+
+	// Unsupported keys are skipped, keeping the usable ones
+	keys, err := echopen.ParseJWKS(jwks)
+	if assert.NoError(t, err) && assert.Len(t, keys, 1) {
+		assert.Equal(t, "rsa", keys[0].ID)
+		assert.Equal(t, "RS256", keys[0].Algorithm)
+	}
+
+	cfg := &echopen.JWTConfig{Keys: keys}
+	_, err = cfg.Parse(signJWT(t, "RS256", "rsa", rsaKey, map[string]interface{}{"sub": "user"}))
+	assert.NoError(t, err)
+
+	// A set with nothing usable is still an error
+	_, err = echopen.ParseJWKS([]byte(`{"keys":[{"kty":"OKP","kid":"ed","crv":"Ed25519","x":"AA"}]}`))
+	assert.EqualError(t, err, "echopen: JWKS contains no supported signing keys")
+	_, err = echopen.ParseJWKS([]byte(`{"keys":[]}`))
+	assert.Error(t, err)
+}
+
+func TestSecurityJWTBearer(t *testing.T) {
+	secret := []byte("secret")
+
+	api := echopen.New(
+		"Test",
+		"1.0.0",
+		echopen.WithJWTConfig(&echopen.JWTConfig{
+			Keys: []*echopen.JWTKey{echopen.NewHMACKey("", secret)},
+		}),
+	)
+	api.Spec.GetComponents().AddSecurityScheme("jwt", &v310.SecurityScheme{
+		Type:         v310.HTTPSecuritySchemeType,
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+	})
+	api.GET(
+		"/",
+		func(c echo.Context) error {
+			claims := c.Get("security.jwt.claims").(*echopen.JWTClaims)
+			assert.Equal(t, "user", claims.Subject)
+			return c.NoContent(204)
+		},
+		echopen.WithSecurityRequirement("jwt", []string{"admin"}),
+	)
+
+	tokens := map[string]int{
+		signJWT(t, "HS256", "", secret, map[string]interface{}{"sub": "user", "scp": []string{"admin"}}): 204,
+		signJWT(t, "HS256", "", secret, map[string]interface{}{"sub": "user", "scp": []string{"user"}}):  403,
+		signJWT(t, "HS256", "", []byte("wrong"), map[string]interface{}{"sub": "user"}):                  401,
+	}
+
+	for token, code := range tokens {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		res := httptest.NewRecorder()
+		api.Engine.ServeHTTP(res, req)
+		assert.Equal(t, code, res.Result().StatusCode)
+	}
+}
@@ -123,6 +123,263 @@ func TestRouteParamParseOverflow(t *testing.T) {
 
 	_, res := executeRequest(api, http.MethodGet, "/1234", nil)
 	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Contains(t, res.Body.String(), `"detail":"path parameter id has an invalid format"`)
+	assert.Contains(t, res.Body.String(), `{"name":"id","in":"path","rule":"format"}`)
+}
+
+func TestRouteParamHeaderMissing(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+	api.GET(
+		"/",
+		func(c echo.Context) error {
+			return c.NoContent(204)
+		},
+		echopen.WithHeaderParameterConfig(&echopen.HeaderParameterConfig{
+			Name:     "X-Request-ID",
+			Required: true,
+		}),
+		echopen.WithCookieParameter("optional", "Optional cookie", ""),
+	)
+
+	_, res := executeRequest(api, http.MethodGet, "/", nil)
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Contains(t, res.Body.String(), `"detail":"required header parameter X-Request-Id is missing"`)
+	assert.Contains(t, res.Body.String(), `{"name":"X-Request-Id","in":"header","rule":"required"}`)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "1234")
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 204, res.Result().StatusCode)
+}
+
+func TestRouteParamSchemaValidation(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+	api.GET(
+		"/orders/:orderId",
+		func(c echo.Context) error {
+			return c.NoContent(204)
+		},
+		echopen.WithPathParameterConfig(&echopen.PathParameterConfig{
+			Name:   "orderId",
+			Schema: &v310.Schema{Type: "integer", Minimum: echopen.PtrTo(1.0), Maximum: echopen.PtrTo(10.0)},
+		}),
+		echopen.WithHeaderParameterConfig(&echopen.HeaderParameterConfig{
+			Name:   "X-Region",
+			Schema: &v310.Schema{Type: "string", Enum: []interface{}{"eu", "us"}},
+		}),
+		echopen.WithCookieParameterConfig(&echopen.CookieParameterConfig{
+			Name:   "session",
+			Schema: &v310.Schema{Type: "string", MinLength: echopen.PtrTo(4), Pattern: "^[a-f0-9]+$"},
+		}),
+	)
+
+	type tcd struct {
+		Name   string
+		Path   string
+		Region string
+		Cookie string
+		Code   int
+		Error  string
+	}
+
+	defs := []tcd{
+		{"valid", "/orders/5", "eu", "abcd", 204, ""},
+		{"minimum", "/orders/0", "", "", 400, `{"name":"orderId","in":"path","rule":"minimum","param":"1"}`},
+		{"maximum", "/orders/11", "", "", 400, `{"name":"orderId","in":"path","rule":"maximum","param":"10"}`},
+		{"enum", "/orders/5", "asia", "", 400, `{"name":"X-Region","in":"header","rule":"enum","param":"[eu us]"}`},
+		{"min_length", "/orders/5", "", "abc", 400, `{"name":"session","in":"cookie","rule":"minLength","param":"4"}`},
+		{"pattern", "/orders/5", "", "wxyz", 400, `{"name":"session","in":"cookie","rule":"pattern","param":"^[a-f0-9]+$"}`},
+	}
+
+	for _, tc := range defs {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.Path, nil)
+			if tc.Region != "" {
+				req.Header.Set("X-Region", tc.Region)
+			}
+			if tc.Cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "session", Value: tc.Cookie})
+			}
+			res := httptest.NewRecorder()
+			api.Engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.Code, res.Result().StatusCode)
+			if tc.Error != "" {
+				assert.Contains(t, res.Body.String(), tc.Error)
+			}
+		})
+	}
+}
+
+func TestRoutePathStruct(t *testing.T) {
+	type OrderPath struct {
+		StoreID string `path:"storeId" description:"Store code" validate:"len=3"`
+		OrderID int64  `path:"orderId" validate:"min=1,max=10"`
+	}
+
+	api := echopen.New("Test", "1.0.0")
+	api.GET(
+		"/stores/:storeId/orders/:orderId",
+		func(c echo.Context) error {
+			path := c.Get("path").(*OrderPath)
+			assert.Equal(t, "abc", path.StoreID)
+			assert.Equal(t, int64(5), path.OrderID)
+			return c.NoContent(204)
+		},
+		echopen.WithPathStruct(OrderPath{}),
+	)
+
+	params := api.Spec.Paths["/stores/{storeId}/orders/{orderId}"].Value.Get.Parameters
+	assert.Len(t, params, 2)
+	assert.Equal(t, "storeId", params[0].Value.Name)
+	assert.Equal(t, "Store code", params[0].Value.Description)
+	assert.True(t, params[0].Value.Required)
+	assert.Equal(t, 10.0, *params[1].Value.Schema.Maximum)
+
+	_, res := executeRequest(api, http.MethodGet, "/stores/abc/orders/5", nil)
+	assert.Equal(t, 204, res.Result().StatusCode)
+
+	_, res = executeRequest(api, http.MethodGet, "/stores/abc/orders/11", nil)
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Contains(t, res.Body.String(), `{"name":"orderId","in":"path","rule":"maximum","param":"10"}`)
+
+	_, res = executeRequest(api, http.MethodGet, "/stores/abcd/orders/5", nil)
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Contains(t, res.Body.String(), `{"name":"storeId","in":"path","rule":"len","param":"3"}`)
+
+	assert.Panics(t, func() {
+		api.GET("/orders/:id", nil, echopen.WithPathStruct(OrderPath{}))
+	})
+}
+
+func TestRouteHeaderCookieStruct(t *testing.T) {
+	type Headers struct {
+		RequestID string   `header:"X-Request-Id" description:"Request identifier" example:"abc123"`
+		Region    *string  `header:"x-region,omitempty" enum:"eu,us"`
+		Accept    []string `header:"Accept,omitempty"`
+	}
+
+	type Cookies struct {
+		Session string `cookie:"session" validate:"min=4"`
+		Theme   string `cookie:"theme,omitempty"`
+	}
+
+	api := echopen.New("Test", "1.0.0")
+	api.GET(
+		"/",
+		func(c echo.Context) error {
+			headers := c.Get("header").(*Headers)
+			cookies := c.Get("cookie").(*Cookies)
+			assert.Equal(t, "abc123", headers.RequestID)
+			assert.Equal(t, "eu", *headers.Region)
+			assert.Equal(t, "abcd", cookies.Session)
+			assert.Equal(t, "dark", cookies.Theme)
+			return c.NoContent(204)
+		},
+		echopen.WithHeaderStruct(Headers{}),
+		echopen.WithCookieStruct(Cookies{}),
+	)
+
+	params := api.Spec.Paths["/"].Value.Get.Parameters
+	assert.Len(t, params, 5)
+	assert.Equal(t, "X-Request-Id", params[0].Value.Name)
+	assert.Equal(t, "Request identifier", params[0].Value.Description)
+	assert.True(t, params[0].Value.Required)
+	assert.Equal(t, []interface{}{"abc123"}, params[0].Value.Schema.Examples)
+	assert.Equal(t, "X-Region", params[1].Value.Name)
+	assert.False(t, params[1].Value.Required)
+	assert.Equal(t, []interface{}{"eu", "us"}, params[1].Value.Schema.Enum)
+	assert.Equal(t, v310.CookieParameter, params[3].Value.In)
+	assert.True(t, params[3].Value.Required)
+	assert.False(t, params[4].Value.Required)
+
+	type tcd struct {
+		Name    string
+		Headers map[string]string
+		Cookies map[string]string
+		Code    int
+		Error   string
+	}
+
+	defs := []tcd{
+		{"valid", map[string]string{"X-Request-Id": "abc123", "X-Region": "eu"}, map[string]string{"session": "abcd", "theme": "dark"}, 204, ""},
+		{"missing_header", map[string]string{}, map[string]string{"session": "abcd"}, 400, `{"name":"X-Request-Id","in":"header","rule":"required"}`},
+		{"enum", map[string]string{"X-Request-Id": "abc123", "X-Region": "asia"}, map[string]string{"session": "abcd"}, 400, `{"name":"X-Region","in":"header","rule":"enum","param":"[eu us]"}`},
+		{"missing_cookie", map[string]string{"X-Request-Id": "abc123"}, map[string]string{}, 400, `{"name":"session","in":"cookie","rule":"required"}`},
+		{"invalid_cookie", map[string]string{"X-Request-Id": "abc123"}, map[string]string{"session": "abc"}, 400, `{"name":"session","in":"cookie","rule":"minLength","param":"4"}`},
+	}
+
+	for _, tc := range defs {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tc.Headers {
+				req.Header.Set(k, v)
+			}
+			for k, v := range tc.Cookies {
+				req.AddCookie(&http.Cookie{Name: k, Value: v})
+			}
+			res := httptest.NewRecorder()
+			api.Engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.Code, res.Result().StatusCode)
+			if tc.Error != "" {
+				assert.Contains(t, res.Body.String(), tc.Error)
+			}
+		})
+	}
+}
+
+func TestRouteHeaderStructArrayEnum(t *testing.T) {
+	type Headers struct {
+		Tags []string `header:"X-Tags" enum:"a,b"`
+	}
+
+	api := echopen.New("Test", "1.0.0")
+	api.GET(
+		"/",
+		func(c echo.Context) error {
+			return c.JSON(200, c.Get("header").(*Headers).Tags)
+		},
+		echopen.WithHeaderStruct(Headers{}),
+	)
+
+	// The enum applies to each item
+	schema := api.Spec.Paths["/"].Value.Get.Parameters[0].Value.Schema
+	assert.Nil(t, schema.Enum)
+	assert.Equal(t, []interface{}{"a", "b"}, schema.Items.Value.Enum)
+
+	type tcd struct {
+		Name   string
+		Values []string
+		Code   int
+	}
+
+	defs := []tcd{
+		{"single", []string{"a"}, 200},
+		{"multiple", []string{"a", "b"}, 200},
+		{"invalid", []string{"c"}, 400},
+		{"invalid_item", []string{"a", "c"}, 400},
+	}
+
+	for _, tc := range defs {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, v := range tc.Values {
+				req.Header.Add("X-Tags", v)
+			}
+			res := httptest.NewRecorder()
+			api.Engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.Code, res.Result().StatusCode)
+			if tc.Code == 400 {
+				assert.Contains(t, res.Body.String(), `"rule":"enum"`)
+			}
+		})
+	}
+}
+
+func TestParameterErrorIs(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &echopen.ParameterError{Name: "id", In: v310.PathParameter, Reason: echopen.ParameterInvalid})
+	assert.ErrorIs(t, err, echopen.ErrRequiredParameterMissing)
+	assert.EqualError(t, err, "wrapped: echopen: path parameter id has an invalid format")
 }
 
 func TestRouteParamHeaderTimestamp(t *testing.T) {
@@ -255,6 +512,70 @@ func TestRequestBody(t *testing.T) {
 	assert.Equal(t, 204, res.Result().StatusCode)
 }
 
+func TestRequestBodyContentType(t *testing.T) {
+	type Body struct {
+		Foo string `json:"foo" xml:"foo"`
+	}
+	api := echopen.New("Test", "1.0.0")
+
+	handler := func(c echo.Context) error {
+		return c.String(200, fmt.Sprint(c.Get("body")))
+	}
+
+	api.POST("/struct", handler, echopen.WithRequestBodyStruct(echo.MIMEApplicationJSON, "Test body", Body{}))
+	api.POST("/xml", handler, echopen.WithRequestBodyStruct(echo.MIMEApplicationXML, "Test body", Body{}))
+	api.POST("/range", handler, echopen.WithRequestBodyStruct("application/*", "Test body", Body{}))
+	api.POST("/optional", handler, echopen.WithRequestBody(&v310.RequestBody{
+		Content: map[string]*v310.MediaTypeObject{
+			echo.MIMEApplicationJSON: {Schema: &v310.Ref[v310.Schema]{Value: &v310.Schema{Type: "object", Required: []string{"foo"}}}},
+			"application/*":          {Schema: &v310.Ref[v310.Schema]{Value: &v310.Schema{Type: "object"}}},
+			"*/*":                    {Schema: &v310.Ref[v310.Schema]{Value: &v310.Schema{}}},
+		},
+	}))
+
+	type tcd struct {
+		Name        string
+		Path        string
+		ContentType string
+		Body        string
+		Code        int
+		Response    string
+	}
+
+	defs := []tcd{
+		{"params", "/struct", "application/json; charset=utf-8", `{"foo":"baz"}`, 200, "&{baz}"},
+		{"mixed_case", "/struct", "Application/JSON", `{"foo":"baz"}`, 200, "&{baz}"},
+		{"invalid_json", "/struct", echo.MIMEApplicationJSON, `{"foo":`, 400, ""},
+		{"xml_mixed_case", "/xml", "Application/XML; charset=utf-8", `<Body><foo>baz</foo></Body>`, 200, "&{baz}"},
+		{"struct_range_json", "/range", "application/vnd.api+json", `{"foo":"baz"}`, 200, "&{baz}"},
+		{"struct_range_xml", "/range", echo.MIMEApplicationXML, `<Body><foo>baz</foo></Body>`, 200, "&{baz}"},
+		{"struct_range_undecodable", "/range", echo.MIMEOctetStream, "foo", 415, ""},
+		{"required_missing", "/struct", "", "", 415, ""},
+		{"unsupported", "/struct", echo.MIMETextPlain, "foo", 415, ""},
+		{"optional_missing", "/optional", "", "", 200, "<nil>"},
+		{"exact", "/optional", echo.MIMEApplicationJSON, `{}`, 400, ""},
+		{"subtype_range", "/optional", "application/vnd.test+json", `{}`, 200, "map[]"},
+		{"any_range", "/optional", echo.MIMETextPlain, "foo", 200, "<nil>"},
+	}
+
+	for _, tc := range defs {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.Path, strings.NewReader(tc.Body))
+			if tc.ContentType != "" {
+				req.Header.Set(echo.HeaderContentType, tc.ContentType)
+			}
+			res := httptest.NewRecorder()
+			api.Engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.Code, res.Result().StatusCode)
+			if tc.Response != "" {
+				assert.Equal(t, tc.Response, res.Body.String())
+			}
+		})
+	}
+
+	assert.True(t, api.Spec.Paths["/struct"].Value.Post.RequestBody.Value.Required)
+}
+
 func TestBaseURL(t *testing.T) {
 	api := echopen.New(
 		"Test",
@@ -298,3 +619,121 @@ func TestBaseURL(t *testing.T) {
 	api.Engine.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
+
+func TestRequestBodyValidationProblem(t *testing.T) {
+	type Body struct {
+		StringLen string `json:"string_len,omitempty" validate:"max=10,min=1"`
+		Nested    struct {
+			NumRange int `json:"num_range" validate:"lte=10"`
+		} `json:"nested"`
+	}
+	api := echopen.New("Test", "1.0.0", echopen.WithProblemDetails())
+	api.POST(
+		"/",
+		func(c echo.Context) error {
+			return c.NoContent(204)
+		},
+		echopen.WithRequestBodyStruct(echo.MIMEApplicationJSON, "Test body", Body{}),
+	)
+
+	assert.Equal(t, "#/components/responses/ProblemDetails", api.Spec.Paths["/"].Value.Post.Responses["400"].Ref)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(
+		`{"string_len":"abcdefghijklmnop","nested":{"num_range":42}}`,
+	))
+	req.Header.Add("Content-Type", echo.MIMEApplicationJSON)
+	res := httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Equal(t, echopen.MIMEApplicationProblemJSON, res.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "request validation failed",
+		"errors": [
+			{"name": "string_len", "in": "body", "rule": "max", "param": "10"},
+			{"name": "nested.num_range", "in": "body", "rule": "lte", "param": "10"}
+		]
+	}`, res.Body.String())
+}
+
+func TestQueryValidationProblem(t *testing.T) {
+	type QueryStruct struct {
+		Limit int `query:"limit" validate:"max=100"`
+	}
+
+	api := echopen.New("Test", "1.0.0")
+	api.GET(
+		"/",
+		func(c echo.Context) error {
+			return c.NoContent(204)
+		},
+		echopen.WithQueryStruct(QueryStruct{}),
+	)
+
+	_, res := executeRequest(api, http.MethodGet, "/?limit=1000", nil)
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Contains(t, res.Body.String(), `{"name":"limit","in":"query","rule":"max","param":"100"}`)
+}
+
+func TestRouteFormStruct(t *testing.T) {
+	type FormStruct struct {
+		Name  string   `form:"name" validate:"required"`
+		Age   int      `form:"age"`
+		Roles []string `form:"roles"`
+	}
+
+	handler := func(c echo.Context) error {
+		form := c.Get("form").(*FormStruct)
+		assert.Equal(t, "joe", form.Name)
+		assert.Equal(t, 42, form.Age)
+		assert.Equal(t, []string{"admin", "user"}, form.Roles)
+		return c.NoContent(204)
+	}
+
+	api := echopen.New("Test", "1.0.0")
+	api.POST("/", handler, echopen.WithFormStruct(FormStruct{}))
+	api.GET("/", handler, echopen.WithFormStruct(FormStruct{}))
+
+	post := api.Spec.Paths["/"].Value.Post
+	assert.Contains(t, post.RequestBody.Value.Content, echo.MIMEApplicationForm)
+	assert.Contains(t, post.RequestBody.Value.Content, echo.MIMEMultipartForm)
+	assert.Equal(t, []string{"name", "age", "roles"}, post.RequestBody.Value.Content[echo.MIMEApplicationForm].Schema.Value.Required)
+	assert.Nil(t, api.Spec.Paths["/"].Value.Get.RequestBody)
+	assert.Len(t, api.Spec.Paths["/"].Value.Get.Parameters, 3)
+
+	// URL encoded body
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=joe&age=42&roles=admin&roles=user"))
+	req.Header.Set("Content-Type", echo.MIMEApplicationForm)
+	res := httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 204, res.Result().StatusCode)
+
+	// Media types are matched case-insensitively, ignoring parameters
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=joe&age=42&roles=admin&roles=user"))
+	req.Header.Set("Content-Type", "Application/X-WWW-Form-Urlencoded; charset=utf-8")
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 204, res.Result().StatusCode)
+
+	// Query params
+	_, res = executeRequest(api, http.MethodGet, "/?name=joe&age=42&roles=admin&roles=user", nil)
+	assert.Equal(t, 204, res.Result().StatusCode)
+
+	// Validation
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("age=42&roles=admin"))
+	req.Header.Set("Content-Type", echo.MIMEApplicationForm)
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Contains(t, res.Body.String(), `{"name":"name","in":"body","rule":"required"}`)
+
+	// Wrong content type
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"joe"}`))
+	req.Header.Set("Content-Type", echo.MIMEApplicationJSON)
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 415, res.Result().StatusCode)
+}
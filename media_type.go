@@ -0,0 +1,113 @@
+package echopen
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mediaRange is a single entry from an Accept header
+type mediaRange struct {
+	Type    string
+	Subtype string
+	Q       float64
+}
+
+// parseMediaType returns the lower case type and subtype of a media type, ignoring any parameters
+func parseMediaType(s string) (string, string) {
+	mt, _, err := mime.ParseMediaType(s)
+	if err != nil {
+		mt = strings.ToLower(strings.TrimSpace(strings.SplitN(s, ";", 2)[0]))
+	}
+	typ, sub, _ := strings.Cut(mt, "/")
+	return typ, sub
+}
+
+// parseAccept parses the media ranges of an Accept header, with a missing header accepting anything
+func parseAccept(accept string) []mediaRange {
+	if strings.TrimSpace(accept) == "" {
+		return []mediaRange{{Type: "*", Subtype: "*", Q: 1}}
+	}
+
+	ranges := []mediaRange{}
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, sub, _ := strings.Cut(mt, "/")
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(qs, 64); err == nil {
+				q = f
+			}
+		}
+		ranges = append(ranges, mediaRange{Type: typ, Subtype: sub, Q: q})
+	}
+	return ranges
+}
+
+// specificity ranks a media range, with exact types above type/* above */*
+func (r mediaRange) specificity() int {
+	if r.Type == "*" {
+		return 0
+	} else if r.Subtype == "*" {
+		return 1
+	}
+	return 2
+}
+
+func (r mediaRange) matches(typ string, sub string) bool {
+	return (r.Type == "*" || r.Type == typ) && (r.Subtype == "*" || r.Subtype == sub)
+}
+
+// negotiate picks the offered media type with the highest quality in the Accept header.
+// The quality of each offer is taken from the most specific matching range, and ties keep the order of the offers.
+// Returns an empty string if nothing offered is acceptable.
+func negotiate(accept string, offers []string) string {
+	ranges := parseAccept(accept)
+
+	// Most specific ranges first
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+
+	best := ""
+	bestQ := 0.0
+	for _, offer := range offers {
+		typ, sub := parseMediaType(offer)
+		for _, r := range ranges {
+			if r.matches(typ, sub) {
+				if r.Q > bestQ {
+					best = offer
+					bestQ = r.Q
+				}
+				break
+			}
+		}
+	}
+	return best
+}
+
+// matchMediaType finds the declared media type, which may be a range, that most specifically matches a request media type.
+// Parameters are ignored on both sides, with exact matches preferred over type/* and then */*.
+func matchMediaType[T any](mt string, declared map[string]T) (string, bool) {
+	typ, sub := parseMediaType(mt)
+
+	best := ""
+	bestSpec := -1
+	for key := range declared {
+		ktyp, ksub := parseMediaType(key)
+		r := mediaRange{Type: ktyp, Subtype: ksub}
+		if !r.matches(typ, sub) {
+			continue
+		}
+		// Ties between keys differing only in parameters are broken alphabetically
+		if spec := r.specificity(); spec > bestSpec || (spec == bestSpec && key < best) {
+			best = key
+			bestSpec = spec
+		}
+	}
+	return best, bestSpec >= 0
+}
@@ -0,0 +1,98 @@
+package echopen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// encodeMsgpack encodes a generic value (as produced by toGeneric) in the MessagePack format.
+// Map keys are sorted so the output is deterministic.
+func encodeMsgpack(buf *bytes.Buffer, v interface{}) error {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if val {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int64:
+		encodeMsgpackInt(buf, val)
+	case float64:
+		buf.WriteByte(0xcb)
+		_ = binary.Write(buf, binary.BigEndian, math.Float64bits(val))
+	case string:
+		encodeMsgpackLength(buf, len(val), 0xa0, 31, 0xd9, 0xda, 0xdb)
+		buf.WriteString(val)
+	case []interface{}:
+		encodeMsgpackLength(buf, len(val), 0x90, 15, 0, 0xdc, 0xdd)
+		for _, item := range val {
+			if err := encodeMsgpack(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		encodeMsgpackLength(buf, len(val), 0x80, 15, 0, 0xde, 0xdf)
+		for _, k := range keys {
+			if err := encodeMsgpack(buf, k); err != nil {
+				return err
+			}
+			if err := encodeMsgpack(buf, val[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("echopen: cannot encode %T as msgpack", v)
+	}
+	return nil
+}
+
+// encodeMsgpackInt uses the smallest integer format which can hold the value
+func encodeMsgpackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= 127:
+		buf.WriteByte(byte(i))
+	case i >= -32 && i < 0:
+		buf.WriteByte(byte(0xe0 | (i + 32)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		_ = binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		_ = binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		_ = binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+// encodeMsgpackLength writes the header for a string, array, or map of the given length.
+// A zero format8 indicates the family has no 8 bit length format.
+func encodeMsgpackLength(buf *bytes.Buffer, n int, fix byte, fixMax int, format8 byte, format16 byte, format32 byte) {
+	switch {
+	case n <= fixMax:
+		buf.WriteByte(fix | byte(n))
+	case format8 != 0 && n <= math.MaxUint8:
+		buf.WriteByte(format8)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(format16)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(format32)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
@@ -0,0 +1,237 @@
+package echopen
+
+import (
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+)
+
+var (
+	fileHeaderType      = reflect.TypeOf(multipart.FileHeader{})
+	fileHeaderPtrType   = reflect.TypeOf(&multipart.FileHeader{})
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader{})
+)
+
+// registerFileValidations adds the "maxsize" and "mimetype" rules for multipart file fields, e.g.
+//
+//	Avatar *multipart.FileHeader `form:"avatar" validate:"required,maxsize=2MB,mimetype=image/png image/jpeg"`
+func registerFileValidations(val *validator.Validate) {
+	_ = val.RegisterValidation("maxsize", validateFileSize)
+	_ = val.RegisterValidation("mimetype", validateFileMIMEType)
+}
+
+// validateFileSize checks every file in the field is no larger than the rule parameter.
+func validateFileSize(fl validator.FieldLevel) bool {
+	// Rules are checked by checkFileValidations when the route is registered
+	max, err := parseFileSize(fl.Param())
+	if err != nil {
+		return false
+	}
+
+	for _, fh := range fieldFileHeaders(fl.Field()) {
+		if fh.Size > max {
+			return false
+		}
+	}
+	return true
+}
+
+// validateFileMIMEType checks the declared content type of every file in the field against the space separated rule parameter.
+// Wildcard subtypes such as image/* are supported.
+func validateFileMIMEType(fl validator.FieldLevel) bool {
+	allowed := strings.Fields(fl.Param())
+
+	for _, fh := range fieldFileHeaders(fl.Field()) {
+		mt, _, err := mime.ParseMediaType(fh.Header.Get(echo.HeaderContentType))
+		if err != nil || !matchesMIMEType(mt, allowed) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesMIMEType(mt string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == mt || (strings.HasSuffix(a, "/*") && strings.HasPrefix(mt, strings.TrimSuffix(a, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFileValidations checks the maxsize and mimetype rules of a form struct, so malformed tags are reported when the route is registered
+func checkFileValidations(t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		isFile := f.Type == fileHeaderPtrType || f.Type == fileHeaderSliceType
+
+		for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+			name, param, _ := strings.Cut(rule, "=")
+			switch name {
+			case "maxsize":
+				if n, err := parseFileSize(param); err != nil || n < 0 {
+					return fmt.Errorf("echopen: invalid maxsize rule %q on field %s", param, f.Name)
+				}
+			case "mimetype":
+				if len(strings.Fields(param)) == 0 {
+					return fmt.Errorf("echopen: mimetype rule on field %s lists no types", f.Name)
+				}
+			default:
+				continue
+			}
+
+			if !isFile {
+				return fmt.Errorf("echopen: %s rule on field %s requires a file field", name, f.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// formValuesAllowance is added to the body limit of a form with file size rules, to cover other values and the multipart framing
+const formValuesAllowance = 1 << 20
+
+// formBodyLimit returns the largest body a form struct can accept, from the maxsize rules of its file fields.
+// File slices are only limited if they also have a max rule for the number of files.
+// Returns 0 if any file field is unlimited, in which case the body is not limited.
+func formBodyLimit(t reflect.Type) int64 {
+	limit := int64(formValuesAllowance)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type != fileHeaderPtrType && f.Type != fileHeaderSliceType {
+			continue
+		}
+
+		size, count := int64(0), int64(0)
+		if f.Type == fileHeaderPtrType {
+			count = 1
+		}
+		for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+			name, param, _ := strings.Cut(rule, "=")
+			switch name {
+			case "maxsize":
+				size, _ = parseFileSize(param)
+			case "max":
+				count, _ = strconv.ParseInt(param, 10, 64)
+			}
+		}
+
+		if size <= 0 || count <= 0 {
+			return 0
+		}
+		limit += size * count
+	}
+
+	if limit == formValuesAllowance {
+		return 0
+	}
+	return limit
+}
+
+// parseFileSize parses a size in bytes, with an optional KB, MB, or GB (binary multiple) suffix
+func parseFileSize(s string) (int64, error) {
+	mult := int64(1)
+	upper := strings.ToUpper(s)
+	for suffix, m := range map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30} {
+		if strings.HasSuffix(upper, suffix) {
+			mult = m
+			s = s[:len(s)-len(suffix)]
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return n * mult, nil
+}
+
+// fieldFileHeaders returns the files held by a *multipart.FileHeader or []*multipart.FileHeader field
+func fieldFileHeaders(v reflect.Value) []*multipart.FileHeader {
+	switch fh := v.Interface().(type) {
+	case *multipart.FileHeader:
+		if fh != nil {
+			return []*multipart.FileHeader{fh}
+		}
+	case multipart.FileHeader:
+		return []*multipart.FileHeader{&fh}
+	case []*multipart.FileHeader:
+		return fh
+	}
+	return nil
+}
+
+// bindFormFiles sets *multipart.FileHeader and []*multipart.FileHeader fields from the parsed multipart form.
+// The echo binder only binds values, so files are matched to fields using the form tag.
+func bindFormFiles(c echo.Context, v interface{}) error {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+
+	val := reflect.ValueOf(v).Elem()
+	typ := val.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		name, _ := ExtractNameTags(typ.Field(i), "form")
+		files, ok := form.File[name]
+		if name == "" || !ok || len(files) == 0 {
+			continue
+		}
+
+		switch typ.Field(i).Type {
+		case fileHeaderPtrType:
+			val.Field(i).Set(reflect.ValueOf(files[0]))
+		case fileHeaderSliceType:
+			val.Field(i).Set(reflect.ValueOf(files))
+		}
+	}
+
+	return nil
+}
+
+// formEncoding returns multipart encoding objects for file properties restricted by a mimetype rule
+func formEncoding(t reflect.Type) map[string]*v310.Encoding {
+	enc := map[string]*v310.Encoding{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type != fileHeaderPtrType && f.Type != fileHeaderSliceType {
+			continue
+		}
+
+		name, _ := ExtractNameTags(f, "form")
+		for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+			if strings.HasPrefix(rule, "mimetype=") {
+				enc[name] = &v310.Encoding{
+					ContentType: strings.Join(strings.Fields(strings.TrimPrefix(rule, "mimetype=")), ", "),
+				}
+			}
+		}
+	}
+
+	if len(enc) == 0 {
+		return nil
+	}
+	return enc
+}
+
+// hasFileFields returns true if the struct type contains any multipart file fields
+func hasFileFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i).Type; f == fileHeaderPtrType || f == fileHeaderSliceType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package echopen_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/richjyoung/echopen"
+	"github.com/stretchr/testify/assert"
+)
+
+type uploadFile struct {
+	Field       string
+	Name        string
+	ContentType string
+	Size        int
+}
+
+func multipartRequest(t *testing.T, fields map[string]string, files []uploadFile) *http.Request {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	for k, v := range fields {
+		assert.NoError(t, w.WriteField(k, v))
+	}
+
+	for _, f := range files {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", `form-data; name="`+f.Field+`"; filename="`+f.Name+`"`)
+		h.Set("Content-Type", f.ContentType)
+		part, err := w.CreatePart(h)
+		assert.NoError(t, err)
+		_, err = part.Write(bytes.Repeat([]byte{'x'}, f.Size))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestRouteFormFiles(t *testing.T) {
+	type UploadForm struct {
+		Title       string                  `form:"title"`
+		Avatar      *multipart.FileHeader   `form:"avatar" validate:"required,maxsize=1KB,mimetype=image/png image/jpeg"`
+		Attachments []*multipart.FileHeader `form:"attachments,omitempty" validate:"omitempty,maxsize=2KB,mimetype=application/pdf text/*"`
+	}
+
+	api := echopen.New("Test", "1.0.0")
+	api.POST("/", func(c echo.Context) error {
+		form := c.Get("form").(*UploadForm)
+		assert.Equal(t, "me", form.Title)
+		assert.Equal(t, "me.png", form.Avatar.Filename)
+		return c.NoContent(204)
+	}, echopen.WithFormStruct(UploadForm{}))
+
+	content := api.Spec.Paths["/"].Value.Post.RequestBody.Value.Content
+	assert.NotContains(t, content, echo.MIMEApplicationForm)
+	assert.Contains(t, content, echo.MIMEMultipartForm)
+
+	schema := content[echo.MIMEMultipartForm].Schema.Value
+	assert.Equal(t, "string", string(schema.Properties["avatar"].Value.Type))
+	assert.Equal(t, "binary", string(schema.Properties["avatar"].Value.Format))
+	assert.Equal(t, "array", string(schema.Properties["attachments"].Value.Type))
+	assert.Equal(t, "binary", string(schema.Properties["attachments"].Value.Items.Value.Format))
+	assert.Equal(t, []string{"title", "avatar"}, schema.Required)
+
+	encoding := content[echo.MIMEMultipartForm].Encoding
+	assert.Equal(t, "image/png, image/jpeg", encoding["avatar"].ContentType)
+	assert.Equal(t, "application/pdf, text/*", encoding["attachments"].ContentType)
+
+	type tcd struct {
+		Name  string
+		Files []uploadFile
+		Code  int
+		Rule  string
+	}
+
+	avatar := uploadFile{"avatar", "me.png", "image/png", 100}
+	defs := []tcd{
+		{"valid", []uploadFile{avatar}, 204, ""},
+		{"attachments", []uploadFile{avatar, {"attachments", "a.pdf", "application/pdf", 100}, {"attachments", "b.txt", "text/plain", 100}}, 204, ""},
+		{"missing", []uploadFile{}, 400, "required"},
+		{"too_large", []uploadFile{{"avatar", "me.png", "image/png", 2048}}, 400, "maxsize"},
+		{"wrong_type", []uploadFile{{"avatar", "me.gif", "image/gif", 100}}, 400, "mimetype"},
+		{"wrong_attachment", []uploadFile{avatar, {"attachments", "a.exe", "application/octet-stream", 100}}, 400, "mimetype"},
+	}
+
+	for _, tc := range defs {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := multipartRequest(t, map[string]string{"title": "me"}, tc.Files)
+			res := httptest.NewRecorder()
+			api.Engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.Code, res.Result().StatusCode)
+			if tc.Rule != "" {
+				assert.Contains(t, res.Body.String(), `"rule":"`+tc.Rule+`"`)
+			}
+		})
+	}
+	// Media types are matched case-insensitively
+	req := multipartRequest(t, map[string]string{"title": "me"}, []uploadFile{avatar})
+	req.Header.Set("Content-Type", strings.Replace(req.Header.Get("Content-Type"), "multipart/form-data", "Multipart/Form-Data", 1))
+	res := httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 204, res.Result().StatusCode)
+}
+
+func TestRouteFormFilesBodyLimit(t *testing.T) {
+	type LimitedForm struct {
+		Avatar *multipart.FileHeader   `form:"avatar" validate:"required,maxsize=1KB"`
+		Photos []*multipart.FileHeader `form:"photos,omitempty" validate:"omitempty,max=2,maxsize=1KB"`
+	}
+	type UnlimitedForm struct {
+		Avatar      *multipart.FileHeader   `form:"avatar" validate:"required,maxsize=1KB"`
+		Attachments []*multipart.FileHeader `form:"attachments,omitempty" validate:"omitempty,maxsize=1KB"`
+	}
+
+	api := echopen.New("Test", "1.0.0")
+	handler := func(c echo.Context) error { return c.NoContent(204) }
+	api.POST("/limited", handler, echopen.WithFormStruct(LimitedForm{}))
+	api.POST("/unlimited", handler, echopen.WithFormStruct(UnlimitedForm{}))
+
+	type tcd struct {
+		Name          string
+		Path          string
+		Size          int
+		ContentLength bool
+		Code          int
+	}
+
+	defs := []tcd{
+		{"valid", "/limited", 100, true, 204},
+		{"content_length", "/limited", 2 << 20, true, 413},
+		{"streamed", "/limited", 2 << 20, false, 413},
+		{"streamed_valid", "/limited", 100, false, 204},
+		// File slices without a max count cannot be limited, so the file is read and fails the maxsize rule instead
+		{"unlimited", "/unlimited", 2 << 20, false, 400},
+	}
+
+	for _, tc := range defs {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := multipartRequest(t, nil, []uploadFile{{"avatar", "me.png", "image/png", tc.Size}})
+			req.URL.Path = tc.Path
+			if !tc.ContentLength {
+				req.ContentLength = -1
+			}
+			res := httptest.NewRecorder()
+			api.Engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.Code, res.Result().StatusCode)
+		})
+	}
+}
+
+func TestRouteFormFilesQuery(t *testing.T) {
+	type UploadForm struct {
+		Avatar *multipart.FileHeader `form:"avatar"`
+	}
+
+	api := echopen.New("Test", "1.0.0")
+	assert.Panics(t, func() {
+		api.GET("/", nil, echopen.WithFormStruct(UploadForm{}))
+	})
+}
+
+func TestRouteFormFilesInvalidRule(t *testing.T) {
+	type UploadForm struct {
+		Avatar *multipart.FileHeader `form:"avatar" validate:"maxsize=lots"`
+	}
+
+	assert.PanicsWithError(t, `echopen: invalid maxsize rule "lots" on field Avatar`, func() {
+		echopen.WithFormStruct(UploadForm{})
+	})
+}
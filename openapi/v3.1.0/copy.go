@@ -0,0 +1,88 @@
+package v310
+
+import "reflect"
+
+var reflectTypeType = reflect.TypeOf((*reflect.Type)(nil)).Elem()
+
+// copier deep copies values of the object graph, remembering each pointer copied so shared objects remain shared.
+type copier struct {
+	seen map[copyKey]reflect.Value
+}
+
+type copyKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// copy returns a deep copy of the value.
+// Pointers, slices, maps, and interface values are copied recursively, whereas reflect.Type values (such as
+// Schema.SourceType) describe Go types rather than the spec so are kept as they are.
+func (c *copier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+
+		key := copyKey{ptr: v.Pointer(), typ: v.Type()}
+		if dst, ok := c.seen[key]; ok {
+			return dst
+		}
+
+		// Record the copy before filling it, so cycles resolve to the same pointer
+		dst := reflect.New(v.Type().Elem())
+		c.seen[key] = dst
+		dst.Elem().Set(c.copy(v.Elem()))
+		return dst
+
+	case reflect.Interface:
+		if v.IsNil() || v.Type() == reflectTypeType || v.Elem().Type().Implements(reflectTypeType) {
+			return v
+		}
+		dst := reflect.New(v.Type()).Elem()
+		dst.Set(c.copy(v.Elem()))
+		return dst
+
+	case reflect.Struct:
+		// Start from a shallow copy so unexported fields (e.g. in time.Time) are kept
+		dst := reflect.New(v.Type()).Elem()
+		dst.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				dst.Field(i).Set(c.copy(v.Field(i)))
+			}
+		}
+		return dst
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		dst := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			dst.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return dst
+
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		dst := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(c.copy(iter.Key()), c.copy(iter.Value()))
+		}
+		return dst
+
+	case reflect.Array:
+		dst := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			dst.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return dst
+
+	default:
+		return v
+	}
+}
@@ -46,18 +46,18 @@ type Paths map[string]*Ref[PathItem]
 
 // 4.8.9 https://spec.openapis.org/oas/v3.1.0#path-item-object
 type PathItem struct {
-	Summary     string          `json:"summary,omitempty" yaml:"summary,omitempty"`
-	Description string          `json:"description,omitempty" yaml:"description,omitempty"`
-	Get         *Operation      `json:"get,omitempty" yaml:"get,omitempty"`
-	Put         *Operation      `json:"put,omitempty" yaml:"put,omitempty"`
-	Post        *Operation      `json:"post,omitempty" yaml:"post,omitempty"`
-	Delete      *Operation      `json:"delete,omitempty" yaml:"delete,omitempty"`
-	Options     *Operation      `json:"options,omitempty" yaml:"options,omitempty"`
-	Head        *Operation      `json:"head,omitempty" yaml:"head,omitempty"`
-	Patch       *Operation      `json:"patch,omitempty" yaml:"patch,omitempty"`
-	Trace       *Operation      `json:"trace,omitempty" yaml:"trace,omitempty"`
-	Servers     []*Server       `json:"servers,omitempty" yaml:"servers,omitempty"`
-	Parameters  *Ref[Parameter] `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Summary     string            `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Get         *Operation        `json:"get,omitempty" yaml:"get,omitempty"`
+	Put         *Operation        `json:"put,omitempty" yaml:"put,omitempty"`
+	Post        *Operation        `json:"post,omitempty" yaml:"post,omitempty"`
+	Delete      *Operation        `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Options     *Operation        `json:"options,omitempty" yaml:"options,omitempty"`
+	Head        *Operation        `json:"head,omitempty" yaml:"head,omitempty"`
+	Patch       *Operation        `json:"patch,omitempty" yaml:"patch,omitempty"`
+	Trace       *Operation        `json:"trace,omitempty" yaml:"trace,omitempty"`
+	Servers     []*Server         `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Parameters  []*Ref[Parameter] `json:"parameters,omitempty" yaml:"parameters,omitempty"`
 }
 
 // 4.8.11 https://spec.openapis.org/oas/v3.1.0#external-documentation-object
@@ -110,7 +110,7 @@ type MediaTypeObject struct {
 
 // 4.8.15 https://spec.openapis.org/oas/v3.1.0#encoding-object
 type Encoding struct {
-	ContentType   string                  `json:"content_type,omitempty" yaml:"content_type,omitempty"`
+	ContentType   string                  `json:"contentType,omitempty" yaml:"contentType,omitempty"`
 	Headers       map[string]*Ref[Header] `json:"headers,omitempty" yaml:"headers,omitempty"`
 	Style         string                  `json:"style,omitempty" yaml:"style,omitempty"`
 	Explode       bool                    `json:"explode,omitempty" yaml:"explode,omitempty"`
@@ -130,12 +130,25 @@ type Example struct {
 
 // 4.8.20 https://spec.openapis.org/oas/v3.1.0#link-object
 type Link struct {
-	TODO
+	OperationRef string                 `json:"operationRef,omitempty" yaml:"operationRef,omitempty"`
+	OperationID  string                 `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Parameters   map[string]interface{} `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody  interface{}            `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Description  string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Server       *Server                `json:"server,omitempty" yaml:"server,omitempty"`
 }
 
 // 4.8.21 https://spec.openapis.org/oas/v3.1.0#header-object
 type Header struct {
-	TODO
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool   `json:"required,omitempty" yaml:"required,omitempty"`
+	Deprecated  bool   `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+
+	Style   string  `json:"style,omitempty" yaml:"style,omitempty"`
+	Explode bool    `json:"explode,omitempty" yaml:"explode,omitempty"`
+	Schema  *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+
+	Content map[string]*MediaTypeObject `json:"content,omitempty" yaml:"content,omitempty"`
 }
 
 // 4.8.22 https://spec.openapis.org/oas/v3.1.0#tag-object
@@ -147,7 +160,8 @@ type Tag struct {
 
 // 4.8.25 https://spec.openapis.org/oas/v3.1.0#discriminator-object
 type Discriminator struct {
-	TODO
+	PropertyName string            `json:"propertyName" yaml:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty" yaml:"mapping,omitempty"`
 }
 
 // 4.8.26 https://spec.openapis.org/oas/v3.1.0#xml-object
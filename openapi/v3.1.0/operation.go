@@ -1,5 +1,11 @@
 package v310
 
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
 // 4.8.10 https://spec.openapis.org/oas/v3.1.0#operation-object
 type Operation struct {
 	OperationID  string                    `json:"operationId,omitempty" yaml:"operationId,omitempty"`
@@ -16,6 +22,44 @@ type Operation struct {
 	Servers      []*Server                 `json:"servers,omitempty" yaml:"servers,omitempty"`
 }
 
+// operationAlias has the fields of Operation without its marshalling methods
+type operationAlias Operation
+
+// MarshalJSON writes an empty security list rather than omitting it, as it opts the operation out of the top level requirements
+func (o *Operation) MarshalJSON() ([]byte, error) {
+	if o.Security == nil || len(o.Security) > 0 {
+		return json.Marshal((*operationAlias)(o))
+	}
+
+	// The outer security field hides the one in the alias
+	return json.Marshal(struct {
+		*operationAlias
+		Security []*SecurityRequirement `json:"security"`
+	}{(*operationAlias)(o), o.Security})
+}
+
+// MarshalYAML writes an empty security list rather than omitting it, in the same way as MarshalJSON
+func (o *Operation) MarshalYAML() (interface{}, error) {
+	if o.Security == nil || len(o.Security) > 0 {
+		return (*operationAlias)(o), nil
+	}
+
+	node := &yaml.Node{}
+	if err := node.Encode((*operationAlias)(o)); err != nil {
+		return nil, err
+	}
+
+	// Keep the field order, with security before servers
+	key := &yaml.Node{Kind: yaml.ScalarNode, Value: "security"}
+	empty := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+	i := len(node.Content)
+	if len(node.Content) >= 2 && node.Content[len(node.Content)-2].Value == "servers" {
+		i -= 2
+	}
+	node.Content = append(node.Content[:i], append([]*yaml.Node{key, empty}, node.Content[i:]...)...)
+	return node, nil
+}
+
 func (o *Operation) AddParameter(param *Parameter) {
 	o.Parameters = append(o.Parameters, &Ref[Parameter]{Value: param})
 }
@@ -2,15 +2,32 @@ package v310
 
 import (
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 // 4.8.23 https://spec.openapis.org/oas/v3.1.0#reference-object
+// Summary and Description override those of the referenced component, and are only used alongside Ref.
 type Ref[T any] struct {
-	Ref   string
-	Value *T
+	Ref         string
+	Summary     string
+	Description string
+	Value       *T
+}
+
+// refObject is the encoded form of a reference object
+type refObject struct {
+	Ref         string `json:"$ref" yaml:"$ref"`
+	Summary     string `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
 }
 
+// DeRef returns the value, or the component the ref points to.
+// Returns nil if the ref is not of the form #/components/<type>/<name>, or the component does not exist.
 func (r *Ref[T]) DeRef(c *Components) interface{} {
 	if r.Value != nil {
 		return r.Value
@@ -18,9 +35,10 @@ func (r *Ref[T]) DeRef(c *Components) interface{} {
 		return nil
 	}
 
-	parts := strings.Split(r.Ref, "/")
-	typ := parts[2]
-	name := parts[3]
+	typ, name, ok := parseComponentRef(r.Ref)
+	if !ok {
+		return nil
+	}
 
 	switch typ {
 	case "schemas":
@@ -44,17 +62,107 @@ func (r *Ref[T]) DeRef(c *Components) interface{} {
 	case "pathItems":
 		return c.PathItems[name]
 	default:
-		panic("unknown component type in ref")
+		return nil
 	}
 }
 
+// parseComponentRef splits a local ref of the form #/components/<type>/<name> into the component type and name
+func parseComponentRef(ref string) (string, string, bool) {
+	const prefix = "#/components/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", "", false
+	}
+
+	typ, name, ok := strings.Cut(strings.TrimPrefix(ref, prefix), "/")
+	if !ok || typ == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", false
+	}
+
+	// Unescape the JSON pointer token (RFC 6901)
+	name = strings.ReplaceAll(strings.ReplaceAll(name, "~1", "/"), "~0", "~")
+	return typ, name, true
+}
+
+// refChecker is implemented by every Ref, so refs can be found by walking the spec
+type refChecker interface {
+	checkRef(c *Components) error
+}
+
+func (r *Ref[T]) checkRef(c *Components) error {
+	if r.Value != nil || r.Ref == "" {
+		return nil
+	} else if _, _, ok := parseComponentRef(r.Ref); !ok {
+		return fmt.Errorf("v310: unsupported $ref %s, only #/components/<type>/<name> refs are supported", r.Ref)
+	} else if v, ok := r.DeRef(c).(*T); !ok || v == nil {
+		return fmt.Errorf("v310: $ref %s does not resolve to a %s component", r.Ref, reflect.TypeOf((*T)(nil)).Elem().Name())
+	}
+	return nil
+}
+
+// checkRefs walks the object graph, checking every ref found resolves through the components
+func checkRefs(v reflect.Value, c *Components, seen map[copyKey]bool) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+
+		key := copyKey{ptr: v.Pointer(), typ: v.Type()}
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+
+		if rc, ok := v.Interface().(refChecker); ok {
+			if err := rc.checkRef(c); err != nil {
+				return err
+			}
+		} else if s, ok := v.Interface().(*Schema); ok && s.Ref != "" {
+			// Schemas with keywords alongside $ref keep it on the schema itself
+			if err := (&Ref[Schema]{Ref: s.Ref}).checkRef(c); err != nil {
+				return err
+			}
+		}
+		return checkRefs(v.Elem(), c, seen)
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				if err := checkRefs(v.Field(i), c, seen); err != nil {
+					return err
+				}
+			}
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := checkRefs(v.Index(i), c, seen); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		// Check in key order so the same error is reported each time
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			if err := checkRefs(v.MapIndex(k), c, seen); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Interface values (such as defaults and examples) hold plain data rather than spec objects
+	return nil
+}
+
 func (r *Ref[T]) MarshalJSON() ([]byte, error) {
 	if r.Ref != "" && r.Value != nil {
 		panic("not implemented")
 	} else if r.Ref != "" {
-		return json.Marshal(map[string]interface{}{
-			"$ref": r.Ref,
-		})
+		return json.Marshal(refObject{Ref: r.Ref, Summary: r.Summary, Description: r.Description})
 	} else {
 		return json.Marshal(r.Value)
 	}
@@ -64,10 +172,86 @@ func (r *Ref[T]) MarshalYAML() (interface{}, error) {
 	if r.Ref != "" && r.Value != nil {
 		panic("not implemented")
 	} else if r.Ref != "" {
-		return map[string]interface{}{
-			"$ref": r.Ref,
-		}, nil
+		return refObject{Ref: r.Ref, Summary: r.Summary, Description: r.Description}, nil
 	} else {
 		return r.Value, nil
 	}
 }
+
+// UnmarshalJSON decodes either a reference object, or the value itself
+func (r *Ref[T]) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err == nil {
+		if _, ok := fields["$ref"]; ok {
+			keys := make([]string, 0, len(fields))
+			for k := range fields {
+				keys = append(keys, k)
+			}
+
+			if isRef, err := r.checkSiblings(keys); err != nil {
+				return err
+			} else if isRef {
+				var ref refObject
+				if err := json.Unmarshal(data, &ref); err != nil {
+					return err
+				}
+				r.Ref, r.Summary, r.Description = ref.Ref, ref.Summary, ref.Description
+				return nil
+			}
+		}
+	}
+
+	r.Value = new(T)
+	return json.Unmarshal(data, r.Value)
+}
+
+// UnmarshalYAML decodes either a reference object, or the value itself
+func (r *Ref[T]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	if node.Kind == yaml.MappingNode {
+		keys := []string{}
+		hasRef := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keys = append(keys, node.Content[i].Value)
+			hasRef = hasRef || node.Content[i].Value == "$ref"
+		}
+
+		if hasRef {
+			if isRef, err := r.checkSiblings(keys); err != nil {
+				return err
+			} else if isRef {
+				var ref refObject
+				if err := node.Decode(&ref); err != nil {
+					return err
+				}
+				r.Ref, r.Summary, r.Description = ref.Ref, ref.Summary, ref.Description
+				return nil
+			}
+		}
+	}
+
+	r.Value = new(T)
+	return node.Decode(r.Value)
+}
+
+// checkSiblings checks the keys of an object containing $ref, returning true if it is a plain reference object.
+// Schemas may have other keywords alongside $ref, in which case they are decoded as a schema value which keeps the $ref.
+// Other objects only allow summary and description, and any other key is an error rather than being silently dropped.
+func (r *Ref[T]) checkSiblings(keys []string) (bool, error) {
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch k {
+		case "$ref", "summary", "description":
+			continue
+		}
+
+		if _, ok := any(new(T)).(*Schema); ok {
+			return false, nil
+		}
+		return false, fmt.Errorf("v310: unsupported key %s alongside $ref", k)
+	}
+	return true, nil
+}
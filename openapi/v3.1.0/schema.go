@@ -1,31 +1,44 @@
 package v310
 
 import (
+	"bytes"
+	"encoding/json"
 	"reflect"
 	"strconv"
 	"time"
 
 	"github.com/gofrs/uuid"
+	"gopkg.in/yaml.v3"
 )
 
 // 4.8.24 https://spec.openapis.org/oas/v3.1.0#schema-object
 type Schema struct {
+	// Ref is only set for schemas with other keywords alongside $ref, otherwise the ref is held by Ref[Schema]
+	Ref         string         `json:"$ref,omitempty" yaml:"$ref,omitempty"`
 	Title       string         `json:"title,omitempty" yaml:"title,omitempty"`
 	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
 	Default     interface{}    `json:"default,omitempty" yaml:"default,omitempty"`
 	Deprecated  bool           `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
-	ReadOnly    bool           `json:"read_only,omitempty" yaml:"read_only,omitempty"`
-	WriteOnly   bool           `json:"write_only,omitempty" yaml:"write_only,omitempty"`
+	ReadOnly    bool           `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+	WriteOnly   bool           `json:"writeOnly,omitempty" yaml:"writeOnly,omitempty"`
 	Examples    []interface{}  `json:"examples,omitempty" yaml:"examples,omitempty"`
 	AllOf       []*Ref[Schema] `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+	AnyOf       []*Ref[Schema] `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+	OneOf       []*Ref[Schema] `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	Not         *Ref[Schema]   `json:"not,omitempty" yaml:"not,omitempty"`
 	XML         *XML           `json:"xml,omitempty" yaml:"xml,omitempty"`
 	SourceType  reflect.Type   `json:"-" yaml:"-"`
 
+	// Boolean is set for the schemas true (any value is valid) and false (no value is valid), in which case all other fields are ignored
+	Boolean *bool `json:"-" yaml:"-"`
+
 	Type   SchemaType   `json:"type,omitempty" yaml:"type,omitempty"`
 	Format SchemaFormat `json:"format,omitempty" yaml:"format,omitempty"`
-	Enum   []string     `json:"enum,omitempty" yaml:"enum,omitempty"`
-	Const  interface{}  `json:"const,omitempty" yaml:"const,omitempty"`
-	Items  *Ref[Schema] `json:"items,omitempty" yaml:"items,omitempty"`
+	// Types lists the allowed types when there is more than one (e.g. ["string", "null"]), and takes precedence over Type
+	Types []SchemaType  `json:"-" yaml:"-"`
+	Enum  []interface{} `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Const interface{}   `json:"const,omitempty" yaml:"const,omitempty"`
+	Items *Ref[Schema]  `json:"items,omitempty" yaml:"items,omitempty"`
 
 	// Numeric
 	MultipleOf       *float64 `json:"multipleOf,omitempty" yaml:"multipleOf,omitempty"`
@@ -85,11 +98,166 @@ func NewSchemaRef(s string) *Ref[Schema] {
 	return &Ref[Schema]{Ref: s}
 }
 
+// NewBooleanSchema returns the schema true, which any value satisfies, or false, which no value satisfies.
+// Commonly used to disallow additionalProperties.
+func NewBooleanSchema(b bool) *Ref[Schema] {
+	return &Ref[Schema]{Value: &Schema{Boolean: &b}}
+}
+
+// schemaAlias has the fields of Schema without its marshalling methods
+type schemaAlias Schema
+
+// MarshalJSON writes boolean schemas as true or false, and type arrays where more than one type is allowed
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.Boolean != nil {
+		return json.Marshal(*s.Boolean)
+	} else if len(s.Types) == 0 {
+		return json.Marshal((*schemaAlias)(s))
+	}
+
+	// The outer type field hides the one in the alias
+	return json.Marshal(struct {
+		Type []SchemaType `json:"type"`
+		*schemaAlias
+	}{s.Types, (*schemaAlias)(s)})
+}
+
+// MarshalYAML writes boolean schemas as true or false, and type arrays where more than one type is allowed
+func (s *Schema) MarshalYAML() (interface{}, error) {
+	if s.Boolean != nil {
+		return *s.Boolean, nil
+	} else if len(s.Types) == 0 {
+		return (*schemaAlias)(s), nil
+	}
+
+	alias := *(*schemaAlias)(s)
+	alias.Type = ""
+
+	node := &yaml.Node{}
+	if err := node.Encode(&alias); err != nil {
+		return nil, err
+	}
+	types := &yaml.Node{}
+	if err := types.Encode(s.Types); err != nil {
+		return nil, err
+	}
+	node.Content = append([]*yaml.Node{{Kind: yaml.ScalarNode, Value: "type"}, types}, node.Content...)
+	return node, nil
+}
+
+// UnmarshalJSON reads boolean schemas, and type given as either a single type or an array
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	if b, err := strconv.ParseBool(string(bytes.TrimSpace(data))); err == nil {
+		*s = Schema{Boolean: &b}
+		return nil
+	}
+
+	aux := struct {
+		Type json.RawMessage `json:"type,omitempty"`
+		*schemaAlias
+	}{schemaAlias: (*schemaAlias)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if len(aux.Type) > 0 {
+		types := []SchemaType{}
+		if aux.Type[0] == '[' {
+			if err := json.Unmarshal(aux.Type, &types); err != nil {
+				return err
+			}
+		} else {
+			var t SchemaType
+			if err := json.Unmarshal(aux.Type, &t); err != nil {
+				return err
+			}
+			types = append(types, t)
+		}
+		s.setTypes(types)
+	}
+	return nil
+}
+
+// UnmarshalYAML reads boolean schemas, and type given as either a single type or a sequence
+func (s *Schema) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!bool" {
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return err
+		}
+		*s = Schema{Boolean: &b}
+		return nil
+	}
+
+	// Type sequences are decoded separately, as the alias only holds a single type
+	rest := *node
+	rest.Content = nil
+	types := []SchemaType{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "type" && node.Content[i+1].Kind == yaml.SequenceNode {
+			if err := node.Content[i+1].Decode(&types); err != nil {
+				return err
+			}
+			continue
+		}
+		rest.Content = append(rest.Content, node.Content[i], node.Content[i+1])
+	}
+
+	if err := rest.Decode((*schemaAlias)(s)); err != nil {
+		return err
+	}
+	if len(types) > 0 {
+		s.setTypes(types)
+	}
+	return nil
+}
+
+// setTypes stores a single type in Type, and multiple types in Types
+func (s *Schema) setTypes(types []SchemaType) {
+	s.Type = ""
+	s.Types = nil
+	if len(types) == 1 {
+		s.Type = types[0]
+	} else if len(types) > 1 {
+		s.Types = types
+	}
+}
+
+// AllowedTypes returns the types a value may have, or nil if any type is allowed
+func (s *Schema) AllowedTypes() []SchemaType {
+	if len(s.Types) > 0 {
+		return s.Types
+	} else if s.Type != "" {
+		return []SchemaType{s.Type}
+	}
+	return nil
+}
+
 func (s *Schema) FromString(val string) interface{} {
 	if s == nil {
 		return val
 	}
 
+	// With several types, the first one the value converts to is used
+	if len(s.Types) > 0 {
+		for _, t := range s.Types {
+			if t == NullSchemaType {
+				continue
+			}
+			single := *s
+			single.Type = t
+			single.Types = nil
+			if v := single.FromString(val); v != nil {
+				return v
+			}
+		}
+		return nil
+	}
+
 	switch s.Type {
 	case "string":
 		switch s.Format {
@@ -180,7 +348,7 @@ func (s *Schema) FromString(val string) interface{} {
 			}
 			return f
 		}
-	case "bool":
+	case "boolean":
 		b, err := strconv.ParseBool(val)
 		if err != nil {
 			return nil
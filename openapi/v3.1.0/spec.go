@@ -1,8 +1,13 @@
 package v310
 
 import (
-	"bytes"
-	"encoding/gob"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 // https://spec.openapis.org/oas/v3.1.0#openapi-object
@@ -31,16 +36,51 @@ func NewSpecification() *Specification {
 	}
 }
 
-func (d *Specification) Copy() *Specification {
-	dest := &Specification{}
-	buf := bytes.Buffer{}
-	if err := gob.NewEncoder(&buf).Encode(d); err != nil {
-		return nil
+// LoadFile reads a specification from a file, decoding it as JSON if the extension is .json and YAML otherwise.
+func LoadFile(path string) (*Specification, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ParseJSON(data)
 	}
-	if err := gob.NewDecoder(&buf).Decode(dest); err != nil {
+	return ParseYAML(data)
+}
+
+// ParseJSON decodes a JSON specification
+func ParseJSON(data []byte) (*Specification, error) {
+	d := &Specification{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// ParseYAML decodes a YAML specification
+func ParseYAML(data []byte) (*Specification, error) {
+	d := &Specification{}
+	if err := yaml.Unmarshal(data, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// CheckRefs checks that every $ref in the specification resolves to a component of the expected type.
+// Only local refs of the form #/components/<type>/<name> are supported.
+func (s *Specification) CheckRefs() error {
+	return checkRefs(reflect.ValueOf(s), s.Components, map[copyKey]bool{})
+}
+
+// Copy returns a deep copy of the specification, which can be modified without affecting the original.
+// Objects shared between several places in the original (such as a schema used by more than one $ref) are also shared in the copy,
+// and SourceType is kept as the same reflect.Type.
+func (d *Specification) Copy() *Specification {
+	if d == nil {
 		return nil
 	}
-	return dest
+	c := &copier{seen: map[copyKey]reflect.Value{}}
+	return c.copy(reflect.ValueOf(d)).Interface().(*Specification)
 }
 
 func (d *Specification) GetComponents() *Components {
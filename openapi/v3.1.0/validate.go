@@ -0,0 +1,381 @@
+package v310
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// SchemaError describes a value which does not satisfy a schema keyword.
+// Path is a JSON pointer to the failing value within a validated JSON document, and is empty for the root.
+type SchemaError struct {
+	Path    string
+	Keyword string
+	Param   string
+}
+
+func (e *SchemaError) Error() string {
+	msg := fmt.Sprintf("value failed schema keyword %s", e.Keyword)
+	if e.Param != "" {
+		msg = fmt.Sprintf("%s=%s", msg, e.Param)
+	}
+	if e.Path != "" {
+		msg = fmt.Sprintf("%s at %s", msg, e.Path)
+	}
+	return msg
+}
+
+var patternCache sync.Map
+
+// Validate checks a value converted with FromString against the enum, numeric, string, and array keywords of the schema.
+// The components are used to resolve array item refs, and may be nil.
+func (s *Schema) Validate(val interface{}, c *Components) error {
+	if s == nil || val == nil {
+		return nil
+	} else if s.Boolean != nil {
+		return s.validateBoolean()
+	}
+
+	if len(s.Enum) > 0 {
+		str := validationString(val)
+		found := false
+		for _, e := range s.Enum {
+			if validationString(e) == str {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &SchemaError{Keyword: "enum", Param: fmt.Sprint(s.Enum)}
+		}
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return s.validateNumber(float64(rv.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return s.validateNumber(float64(rv.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return s.validateNumber(rv.Float())
+	case reflect.String:
+		return s.validateString(rv.String())
+	case reflect.Slice:
+		return s.validateArray(rv, c)
+	}
+
+	return nil
+}
+
+func (s *Schema) validateNumber(n float64) error {
+	if s.Minimum != nil && n < *s.Minimum {
+		return &SchemaError{Keyword: "minimum", Param: fmt.Sprint(*s.Minimum)}
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		return &SchemaError{Keyword: "maximum", Param: fmt.Sprint(*s.Maximum)}
+	}
+	if s.ExclusiveMinimum != nil && n <= *s.ExclusiveMinimum {
+		return &SchemaError{Keyword: "exclusiveMinimum", Param: fmt.Sprint(*s.ExclusiveMinimum)}
+	}
+	if s.ExclusiveMaximum != nil && n >= *s.ExclusiveMaximum {
+		return &SchemaError{Keyword: "exclusiveMaximum", Param: fmt.Sprint(*s.ExclusiveMaximum)}
+	}
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		if q := n / *s.MultipleOf; q != math.Trunc(q) {
+			return &SchemaError{Keyword: "multipleOf", Param: fmt.Sprint(*s.MultipleOf)}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateString(str string) error {
+	// Lengths are measured in characters, not bytes
+	l := utf8.RuneCountInString(str)
+	if s.MinLength != nil && l < *s.MinLength {
+		return &SchemaError{Keyword: "minLength", Param: fmt.Sprint(*s.MinLength)}
+	}
+	if s.MaxLength != nil && l > *s.MaxLength {
+		return &SchemaError{Keyword: "maxLength", Param: fmt.Sprint(*s.MaxLength)}
+	}
+	if s.Pattern != "" {
+		re, err := compilePattern(s.Pattern)
+		if err != nil {
+			return err
+		}
+		if !re.MatchString(str) {
+			return &SchemaError{Keyword: "pattern", Param: s.Pattern}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateArray(rv reflect.Value, c *Components) error {
+	l := rv.Len()
+	if s.MinItems != nil && l < *s.MinItems {
+		return &SchemaError{Keyword: "minItems", Param: fmt.Sprint(*s.MinItems)}
+	}
+	if s.MaxItems != nil && l > *s.MaxItems {
+		return &SchemaError{Keyword: "maxItems", Param: fmt.Sprint(*s.MaxItems)}
+	}
+	if s.UniqueItems && rv.Type().Elem().Comparable() {
+		seen := map[interface{}]bool{}
+		for i := 0; i < l; i++ {
+			item := rv.Index(i).Interface()
+			if seen[item] {
+				return &SchemaError{Keyword: "uniqueItems"}
+			}
+			seen[item] = true
+		}
+	}
+	if s.Items != nil {
+		items, _ := s.Items.DeRef(c).(*Schema)
+		for i := 0; i < l; i++ {
+			if err := items.Validate(rv.Index(i).Interface(), c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateBoolean fails if the schema is false, which no value satisfies
+func (s *Schema) validateBoolean() error {
+	if !*s.Boolean {
+		return &SchemaError{Keyword: "false"}
+	}
+	return nil
+}
+
+// validationString formats a value the same way it would appear in an enum
+func validationString(val interface{}) string {
+	if t, ok := val.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(val)
+}
+
+// compilePattern compiles and caches a regular expression from a pattern keyword
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
+
+// ValidateJSON checks a value decoded by encoding/json against the schema, resolving refs through the components.
+func (s *Schema) ValidateJSON(val interface{}, c *Components) error {
+	return s.validateJSON(val, c, "")
+}
+
+func (s *Schema) validateJSON(val interface{}, c *Components, path string) error {
+	if s == nil {
+		return nil
+	} else if s.Boolean != nil {
+		if err := s.validateBoolean(); err != nil {
+			return &SchemaError{Path: path, Keyword: "false"}
+		}
+		return nil
+	}
+
+	// Keywords alongside $ref apply in addition to the referenced schema
+	if s.Ref != "" {
+		sub, _ := (&Ref[Schema]{Ref: s.Ref}).DeRef(c).(*Schema)
+		if err := sub.validateJSON(val, c, path); err != nil {
+			return err
+		}
+	}
+
+	for _, ref := range s.AllOf {
+		sub, _ := ref.DeRef(c).(*Schema)
+		if err := sub.validateJSON(val, c, path); err != nil {
+			return err
+		}
+	}
+
+	if len(s.AnyOf) > 0 && countValid(s.AnyOf, val, c, path) == 0 {
+		return &SchemaError{Path: path, Keyword: "anyOf"}
+	}
+	if len(s.OneOf) > 0 && countValid(s.OneOf, val, c, path) != 1 {
+		return &SchemaError{Path: path, Keyword: "oneOf"}
+	}
+	if s.Not != nil && countValid([]*Ref[Schema]{s.Not}, val, c, path) != 0 {
+		return &SchemaError{Path: path, Keyword: "not"}
+	}
+
+	if types := s.AllowedTypes(); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if matchesJSONType(t, val) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			names := make([]string, len(types))
+			for i, t := range types {
+				names[i] = string(t)
+			}
+			return &SchemaError{Path: path, Keyword: "type", Param: strings.Join(names, ",")}
+		}
+	}
+
+	// Enum values may be of any type, so are compared by their JSON encoding like const
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if jsonEqual(e, val) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &SchemaError{Path: path, Keyword: "enum", Param: fmt.Sprint(s.Enum)}
+		}
+	}
+
+	if s.Const != nil && !jsonEqual(s.Const, val) {
+		return &SchemaError{Path: path, Keyword: "const", Param: fmt.Sprint(s.Const)}
+	}
+
+	var err error
+	switch v := val.(type) {
+	case map[string]interface{}:
+		err = s.validateObject(v, c, path)
+	case []interface{}:
+		err = s.validateJSONArray(v, c, path)
+	default:
+		err = s.Validate(val, c)
+	}
+
+	if se, ok := err.(*SchemaError); ok && se.Path == "" {
+		se.Path = path
+	}
+	return err
+}
+
+func (s *Schema) validateObject(obj map[string]interface{}, c *Components, path string) error {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			return &SchemaError{Path: path, Keyword: "required", Param: name}
+		}
+	}
+	if s.MinProperties != nil && len(obj) < *s.MinProperties {
+		return &SchemaError{Path: path, Keyword: "minProperties", Param: fmt.Sprint(*s.MinProperties)}
+	}
+	if s.MaxProperties != nil && len(obj) > *s.MaxProperties {
+		return &SchemaError{Path: path, Keyword: "maxProperties", Param: fmt.Sprint(*s.MaxProperties)}
+	}
+
+	// Check properties in a stable order so the first failure is reported deterministically
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ref, ok := s.Properties[name]
+		if !ok {
+			// Properties not listed are checked against additionalProperties, if given
+			if ref = s.AdditionalProperties; ref == nil {
+				continue
+			}
+			if prop, _ := ref.DeRef(c).(*Schema); prop != nil && prop.Boolean != nil && !*prop.Boolean {
+				return &SchemaError{Path: path, Keyword: "additionalProperties", Param: name}
+			}
+		}
+		prop, _ := ref.DeRef(c).(*Schema)
+		if err := prop.validateJSON(obj[name], c, path+"/"+jsonPointerEscape(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateJSONArray(arr []interface{}, c *Components, path string) error {
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		return &SchemaError{Path: path, Keyword: "minItems", Param: fmt.Sprint(*s.MinItems)}
+	}
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		return &SchemaError{Path: path, Keyword: "maxItems", Param: fmt.Sprint(*s.MaxItems)}
+	}
+	if s.UniqueItems {
+		for i := range arr {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(arr[i], arr[j]) {
+					return &SchemaError{Path: path, Keyword: "uniqueItems"}
+				}
+			}
+		}
+	}
+	if s.Items != nil {
+		items, _ := s.Items.DeRef(c).(*Schema)
+		for i, item := range arr {
+			if err := items.validateJSON(item, c, fmt.Sprintf("%s/%d", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchesJSONType checks the Go type produced by encoding/json against a schema type
+func matchesJSONType(t SchemaType, val interface{}) bool {
+	switch v := val.(type) {
+	case nil:
+		return t == NullSchemaType
+	case bool:
+		return t == BooleanSchemaType
+	case string:
+		return t == StringSchemaType
+	case float64:
+		return t == NumberSchemaType || (t == IntegerSchemaType && v == math.Trunc(v))
+	case map[string]interface{}:
+		return t == ObjectSchemaType
+	case []interface{}:
+		return t == ArraySchemaType
+	}
+	return false
+}
+
+func jsonPointerEscape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+// countValid returns the number of schemas the value satisfies
+func countValid(refs []*Ref[Schema], val interface{}, c *Components, path string) int {
+	n := 0
+	for _, ref := range refs {
+		sub, _ := ref.DeRef(c).(*Schema)
+		if sub.validateJSON(val, c, path) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// jsonEqual compares values by their JSON encoding, so Go values in the schema match values decoded from a document
+func jsonEqual(a interface{}, b interface{}) bool {
+	ab, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ab, bb)
+}
@@ -1,6 +1,7 @@
 package echopen
 
 import (
+	"fmt"
 	"net/http"
 	"reflect"
 
@@ -82,9 +83,8 @@ func WithPathParameter(name string, description string, example interface{}) Rou
 
 		if example != nil {
 			t := reflect.TypeOf(example)
-			zero := reflect.New(t).Elem().Interface()
 			pathParam.Schema = rw.API.TypeToSchema(t)
-			if example != zero {
+			if !reflect.ValueOf(example).IsZero() {
 				pathParam.Examples = []*v310.Example{
 					{Value: example},
 				}
@@ -104,9 +104,8 @@ func WithHeaderParameter(name string, description string, example interface{}) R
 
 		if example != nil {
 			t := reflect.TypeOf(example)
-			zero := reflect.New(t).Elem().Interface()
 			hdrParam.Schema = rw.API.TypeToSchema(t)
-			if example != zero {
+			if !reflect.ValueOf(example).IsZero() {
 				hdrParam.Examples = []*v310.Example{
 					{Value: example},
 				}
@@ -132,3 +131,108 @@ func WithCookieParameter(name string, description string, example interface{}) R
 		return WithCookieParameterConfig(cookieParam)(rw)
 	}
 }
+
+// WithPathStruct extracts path parameters from the fields of a struct with `path` tags.
+// A bound struct of the same type is added to the context under the key "path" during each request.
+// Panics if a field does not match a parameter in the route path.
+func WithPathStruct(target interface{}) RouteConfigFunc {
+	t := reflect.TypeOf(target)
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("echopen: struct expected, received %s", t.Kind()))
+	}
+
+	return func(rw *RouteWrapper) *RouteWrapper {
+		params := routeParams(rw.Path)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _ := ExtractNameTags(f, "path")
+			if !f.IsExported() {
+				panic(fmt.Errorf("echopen: path struct field %s must be exported", f.Name))
+			} else if !params[name] {
+				panic(fmt.Errorf("echopen: path struct field %s does not match a parameter in route %s", f.Name, rw.Path))
+			}
+		}
+
+		s := rw.API.StructTypeToSchema(t, "path")
+		rw.PathSchema = s
+		rw.addStructParameters(t, s, v310.PathParameter)
+		return rw
+	}
+}
+
+// WithHeaderStruct extracts header parameters from the fields of a struct with `header` tags.
+// A bound struct of the same type is added to the context under the key "header" during each request.
+func WithHeaderStruct(target interface{}) RouteConfigFunc {
+	t := reflect.TypeOf(target)
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("echopen: struct expected, received %s", t.Kind()))
+	}
+
+	return func(rw *RouteWrapper) *RouteWrapper {
+		s := rw.API.StructTypeToSchema(t, "header")
+		rw.HeaderSchema = s
+		rw.addStructParameters(t, s, v310.HeaderParameter)
+		return rw
+	}
+}
+
+// WithCookieStruct extracts cookie parameters from the fields of a struct with `cookie` tags.
+// A bound struct of the same type is added to the context under the key "cookie" during each request.
+func WithCookieStruct(target interface{}) RouteConfigFunc {
+	t := reflect.TypeOf(target)
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("echopen: struct expected, received %s", t.Kind()))
+	}
+
+	return func(rw *RouteWrapper) *RouteWrapper {
+		s := rw.API.StructTypeToSchema(t, "cookie")
+		rw.CookieSchema = s
+		rw.addStructParameters(t, s, v310.CookieParameter)
+		return rw
+	}
+}
+
+// addStructParameters adds a parameter to the operation for each tagged field of the struct, in field order.
+// Path parameters are always required, other locations are required unless the tag includes omitempty.
+func (rw *RouteWrapper) addStructParameters(t reflect.Type, s *v310.Schema, in v310.ParameterLocation) {
+	for i := 0; i < t.NumField(); i++ {
+		name, omitEmpty := ExtractNameTags(t.Field(i), string(in))
+		prop, ok := s.Properties[name]
+		if name == "" || !ok {
+			continue
+		}
+
+		if in == v310.HeaderParameter {
+			name = http.CanonicalHeaderKey(name)
+		}
+
+		param := &v310.Parameter{
+			Name:     name,
+			In:       in,
+			Required: in == v310.PathParameter || !omitEmpty,
+		}
+
+		if prop.Value != nil {
+			// Description belongs to the parameter rather than the schema
+			schema := *prop.Value
+			schema.Description = ""
+			param.Description = prop.Value.Description
+			param.Schema = &schema
+		}
+
+		rw.Operation.AddParameter(param)
+	}
+}
+
+// paramBindType returns a copy of a struct type with each name tag replaced by the equivalent echo binder tag, without options.
+// Values of the copy can be bound by the echo binder, then converted back to the original type.
+func paramBindType(t reflect.Type, nameTag string, bindTag string) reflect.Type {
+	fields := make([]reflect.StructField, t.NumField())
+	for i := range fields {
+		f := t.Field(i)
+		name, _ := ExtractNameTags(f, nameTag)
+		f.Tag = reflect.StructTag(fmt.Sprintf(`%s:"%s"`, bindTag, name))
+		fields[i] = f
+	}
+	return reflect.StructOf(fields)
+}
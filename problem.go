@@ -0,0 +1,178 @@
+package echopen
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+)
+
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 error response body
+type ProblemDetails struct {
+	Type   string                 `json:"type,omitempty" description:"URI reference identifying the problem type"`
+	Title  string                 `json:"title" description:"Short summary of the problem type"`
+	Status int                    `json:"status" description:"HTTP status code"`
+	Detail string                 `json:"detail,omitempty" description:"Explanation specific to this occurrence of the problem"`
+	Errors []*ProblemDetailsError `json:"errors,omitempty" description:"Individual failures which caused the problem"`
+}
+
+// ProblemDetailsError describes a single invalid field or parameter
+type ProblemDetailsError struct {
+	Name  string `json:"name" description:"Field or parameter name as sent in the request"`
+	In    string `json:"in,omitempty" description:"Location of the field or parameter" enum:"body,query,path,header,cookie"`
+	Rule  string `json:"rule,omitempty" description:"Validation rule which failed"`
+	Param string `json:"param,omitempty" description:"Parameter of the failed validation rule"`
+}
+
+// NewValidationProblem builds a 400 ProblemDetails from validator errors, using the names from the bound struct tags
+func NewValidationProblem(in string, ve validator.ValidationErrors) *ProblemDetails {
+	p := &ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusBadRequest),
+		Status: http.StatusBadRequest,
+		Detail: "request validation failed",
+	}
+
+	for _, fe := range ve {
+		p.Errors = append(p.Errors, &ProblemDetailsError{
+			Name:  validationFieldName(fe),
+			In:    in,
+			Rule:  fe.Tag(),
+			Param: fe.Param(),
+		})
+	}
+
+	if len(p.Errors) == 1 {
+		p.Detail = fmt.Sprintf("%s failed validation rule %s", p.Errors[0].Name, p.Errors[0].Rule)
+	}
+
+	return p
+}
+
+// NewSchemaProblem builds a 400 ProblemDetails from a JSON schema validation failure.
+// The failing value is named by its path within the document, with a missing required property named directly.
+func NewSchemaProblem(in string, se *v310.SchemaError) *ProblemDetails {
+	path := se.Path
+	if se.Keyword == "required" {
+		path = path + "/" + se.Param
+	}
+	name := strings.ReplaceAll(strings.TrimPrefix(path, "/"), "/", ".")
+	if name == "" {
+		name = in
+	}
+
+	return &ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusBadRequest),
+		Status: http.StatusBadRequest,
+		Detail: fmt.Sprintf("%s failed validation rule %s", name, se.Keyword),
+		Errors: []*ProblemDetailsError{{
+			Name:  name,
+			In:    in,
+			Rule:  se.Keyword,
+			Param: se.Param,
+		}},
+	}
+}
+
+// WriteProblem sends a ProblemDetails response with the application/problem+json content type
+func WriteProblem(c echo.Context, p *ProblemDetails) error {
+	c.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+	return c.JSON(p.Status, p)
+}
+
+// NewParameterProblem builds a 400 ProblemDetails for a missing or unparsable parameter
+func NewParameterProblem(pe *ParameterError) *ProblemDetails {
+	rule := "required"
+	detail := fmt.Sprintf("required %s parameter %s is missing", pe.In, pe.Name)
+	if pe.Rule != "" {
+		rule = pe.Rule
+		detail = fmt.Sprintf("%s parameter %s failed validation rule %s", pe.In, pe.Name, pe.Rule)
+	} else if pe.Reason == ParameterInvalid {
+		rule = "format"
+		detail = fmt.Sprintf("%s parameter %s has an invalid format", pe.In, pe.Name)
+	}
+
+	return &ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusBadRequest),
+		Status: http.StatusBadRequest,
+		Detail: detail,
+		Errors: []*ProblemDetailsError{{
+			Name:  pe.Name,
+			In:    string(pe.In),
+			Rule:  rule,
+			Param: pe.Param,
+		}},
+	}
+}
+
+// problemFromError converts known validation errors into a ProblemDetails, returning false for any other error
+func problemFromError(err error) (*ProblemDetails, bool) {
+	var pe *ParameterError
+	if errors.As(err, &pe) {
+		return NewParameterProblem(pe), true
+	}
+
+	var se *SchemaValidationError
+	if errors.As(err, &se) {
+		return NewSchemaProblem(se.In, se.Err), true
+	}
+
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) {
+		return nil, false
+	}
+
+	in := ""
+	var vErr *ValidationError
+	if errors.As(err, &vErr) {
+		in = vErr.In
+	}
+
+	return NewValidationProblem(in, ve), true
+}
+
+// RegisterProblemDetails adds the ProblemDetails schema and a matching "ProblemDetails" response to the spec components.
+// Routes added afterwards which bind parameters, query, or body have a 400 response referencing it, unless one is already declared
+func (a *APIWrapper) RegisterProblemDetails() {
+	schema := a.ToSchemaRef(ProblemDetails{})
+	a.Spec.GetComponents().AddResponse("ProblemDetails", &v310.Response{
+		Description: "Request validation failed",
+		Content: map[string]*v310.MediaTypeObject{
+			MIMEApplicationProblemJSON: {Schema: schema},
+		},
+	})
+	a.problemDetails = true
+	a.SpecChanged()
+}
+
+func WithProblemDetails() WrapperConfigFunc {
+	return func(a *APIWrapper) *APIWrapper {
+		a.RegisterProblemDetails()
+		return a
+	}
+}
+
+// addProblemDetailsResponse documents the validation failure response for routes which validate their inputs
+func (r *RouteWrapper) addProblemDetailsResponse() {
+	if !r.API.problemDetails {
+		return
+	}
+
+	if len(r.Operation.Parameters) == 0 && r.QuerySchema == nil && r.FormSchema == nil && len(r.RequestBodySchema) == 0 {
+		return
+	}
+
+	if _, exists := r.Operation.Responses[fmt.Sprint(http.StatusBadRequest)]; exists {
+		return
+	}
+
+	r.Operation.AddResponseRef(fmt.Sprint(http.StatusBadRequest), "#/components/responses/ProblemDetails")
+}
@@ -2,8 +2,10 @@ package echopen
 
 import (
 	"fmt"
+	"net/http"
 	"reflect"
 
+	"github.com/labstack/echo/v4"
 	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
 )
 
@@ -18,23 +20,7 @@ func WithQueryStruct(target interface{}) RouteConfigFunc {
 	return func(rw *RouteWrapper) *RouteWrapper {
 		s := rw.API.StructTypeToSchema(t, "query")
 		rw.QuerySchema = s
-
-		for name, prop := range s.Properties {
-			rw.Operation.AddParameter(&v310.Parameter{
-				Name:        name,
-				In:          "query",
-				Required:    false,
-				Description: prop.Value.Description,
-				Style:       "form",
-				Schema: &v310.Schema{
-					Type:    prop.Value.Type,
-					Items:   prop.Value.Items,
-					Enum:    prop.Value.Enum,
-					Default: prop.Value.Default,
-				},
-			})
-		}
-
+		rw.addQueryParameters(s)
 		return rw
 	}
 }
@@ -43,8 +29,69 @@ func WithQueryStruct(target interface{}) RouteConfigFunc {
 // A bound struct of the same type is added to the context under the key "form" during each request
 // Binding will use either request body or query params (GET/DELETE only)
 func WithFormStruct(target interface{}) RouteConfigFunc {
+	t := reflect.TypeOf(target)
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("echopen: struct expected, received %s", t.Kind()))
+	}
+	if err := checkFileValidations(t); err != nil {
+		panic(err)
+	}
+
 	return func(rw *RouteWrapper) *RouteWrapper {
-		// TODO
+		s := rw.API.StructTypeToSchema(t, "form")
+		rw.FormSchema = s
+
+		if rw.Method == http.MethodGet || rw.Method == http.MethodDelete {
+			if hasFileFields(t) {
+				panic(fmt.Errorf("echopen: file fields cannot be sent as query params for %s routes", rw.Method))
+			}
+
+			// No request body, form is sent as query params
+			rw.addQueryParameters(s)
+			return rw
+		}
+
+		// Form field names differ from the JSON names, so the schema is inlined rather than stored as a component
+		content := map[string]*v310.MediaTypeObject{
+			echo.MIMEMultipartForm: {Schema: &v310.Ref[v310.Schema]{Value: s}, Encoding: formEncoding(t)},
+		}
+
+		// Files can only be uploaded as multipart
+		if !hasFileFields(t) {
+			content[echo.MIMEApplicationForm] = &v310.MediaTypeObject{Schema: &v310.Ref[v310.Schema]{Value: s}}
+		}
+
+		if rw.Operation.RequestBody != nil && rw.Operation.RequestBody.Value != nil {
+			// Add alongside any existing request body content types
+			for mime, mt := range content {
+				rw.Operation.RequestBody.Value.Content[mime] = mt
+			}
+		} else {
+			rw.Operation.AddRequestBody(&v310.RequestBody{
+				Required: true,
+				Content:  content,
+			})
+		}
+
 		return rw
 	}
 }
+
+// addQueryParameters adds a query parameter to the operation for each property of the schema
+func (rw *RouteWrapper) addQueryParameters(s *v310.Schema) {
+	for name, prop := range s.Properties {
+		rw.Operation.AddParameter(&v310.Parameter{
+			Name:        name,
+			In:          "query",
+			Required:    false,
+			Description: prop.Value.Description,
+			Style:       "form",
+			Schema: &v310.Schema{
+				Type:    prop.Value.Type,
+				Items:   prop.Value.Items,
+				Enum:    prop.Value.Enum,
+				Default: prop.Value.Default,
+			},
+		})
+	}
+}
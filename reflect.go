@@ -40,6 +40,7 @@ func (w *APIWrapper) TypeToSchemaRef(typ reflect.Type) *v310.Ref[v310.Schema] {
 					// Named structs can be stored in the Schema library and referenced multiple times
 					w.Spec.GetComponents().AddSchema(name, w.TypeToSchema(typ))
 					w.schemaMap[typ] = fmt.Sprintf("#/components/schemas/%s", name)
+					w.SpecChanged()
 
 					// Return a reference to the schema component
 					return &v310.Ref[v310.Schema]{
@@ -104,6 +105,8 @@ func (w *APIWrapper) TypeToSchema(typ reflect.Type) *v310.Schema {
 		// Get schema for struct including contained fields (assume json)
 		if typ == reflect.TypeOf(time.Time{}) {
 			return &v310.Schema{Type: "string", Format: "date-time", SourceType: typ}
+		} else if typ == fileHeaderType {
+			return &v310.Schema{Type: "string", Format: "binary", SourceType: typ}
 		}
 		return w.StructTypeToSchema(typ, "json")
 	case reflect.Pointer:
@@ -139,8 +142,13 @@ func (w *APIWrapper) StructTypeToSchema(target reflect.Type, nameTag string) *v3
 		// Get SchemaRef for the contained field
 		ref := w.StructFieldToSchemaRef(f)
 
-		// Get the name from the json tag (does assume only JSON is used)
-		_, omitEmpty := ExtractJSONTags(f)
+		// Check for omitempty in the same tag the name was taken from
+		_, omitEmpty := ExtractNameTags(f, nameTag)
+
+		// Nil pointers, slices, and maps are encoded as JSON null unless omitted
+		if nameTag == "json" && !omitEmpty && !f.Anonymous && isNilable(f.Type) {
+			ref = nullableSchemaRef(ref)
+		}
 
 		if f.Anonymous {
 			// Anonymous members of a struct imply composition
@@ -165,6 +173,28 @@ func (w *APIWrapper) StructTypeToSchema(target reflect.Type, nameTag string) *v3
 	return s
 }
 
+// isNilable returns true for types which can hold nil
+func isNilable(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// nullableSchemaRef adds null to the allowed types of a schema, or allows null alongside a referenced schema
+func nullableSchemaRef(ref *v310.Ref[v310.Schema]) *v310.Ref[v310.Schema] {
+	if ref.Value == nil {
+		return &v310.Ref[v310.Schema]{Value: &v310.Schema{
+			AnyOf: []*v310.Ref[v310.Schema]{ref, {Value: &v310.Schema{Type: v310.NullSchemaType}}},
+		}}
+	} else if ref.Value.Type != "" {
+		ref.Value.Types = []v310.SchemaType{ref.Value.Type, v310.NullSchemaType}
+		ref.Value.Type = ""
+	}
+	return ref
+}
+
 func (w *APIWrapper) StructFieldToSchemaRef(f reflect.StructField) *v310.Ref[v310.Schema] {
 	ref := w.TypeToSchemaRef(f.Type)
 
@@ -177,7 +207,17 @@ func (w *APIWrapper) StructFieldToSchemaRef(f reflect.StructField) *v310.Ref[v31
 
 		enum := f.Tag.Get("enum")
 		if enum != "" {
-			ref.Value.Enum = strings.Split(enum, ",")
+			values := []interface{}{}
+			for _, e := range strings.Split(enum, ",") {
+				values = append(values, e)
+			}
+
+			// Enums on slices restrict each item rather than the array as a whole
+			if ref.Value.Type == "array" && ref.Value.Items != nil && ref.Value.Items.Value != nil {
+				ref.Value.Items.Value.Enum = values
+			} else {
+				ref.Value.Enum = values
+			}
 		}
 
 		// Extract validation rules
@@ -188,13 +228,30 @@ func (w *APIWrapper) StructFieldToSchemaRef(f reflect.StructField) *v310.Ref[v31
 		if example != "" {
 			ref.Value.Examples = append(ref.Value.Examples, example)
 		}
+
+		// XML element name and attributes
+		if tag, ok := f.Tag.Lookup("xml"); ok {
+			parts := strings.Split(tag, ",")
+			attr := false
+			for _, p := range parts[1:] {
+				attr = attr || p == "attr"
+			}
+			if parts[0] != "" || attr {
+				ref.Value.XML = &v310.XML{Name: parts[0], Attribute: attr}
+			}
+		}
 	}
 
 	return ref
 }
 
 func ExtractJSONTags(field reflect.StructField) (string, bool) {
-	parts := strings.Split(field.Tag.Get("json"), ",")
+	return ExtractNameTags(field, "json")
+}
+
+// ExtractNameTags returns the name and omitempty flag from the given struct tag (e.g. json, query, or form)
+func ExtractNameTags(field reflect.StructField, nameTag string) (string, bool) {
+	parts := strings.Split(field.Tag.Get(nameTag), ",")
 	name := parts[0]
 	if len(parts) > 1 && parts[1] == "omitempty" {
 		return name, true
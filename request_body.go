@@ -8,7 +8,7 @@ import (
 )
 
 // WithRequestBodyStruct extracts type information from a provided struct to populate the OpenAPI requestBody.
-// A bound struct of the same type is added to the context under the key "body" during each request.
+// The request body is marked as required, and a bound struct of the same type is added to the context under the key "body" during each request.
 func WithRequestBodyStruct(mime string, description string, target interface{}) RouteConfigFunc {
 	t := reflect.TypeOf(target)
 	if t.Kind() != reflect.Struct {
@@ -48,6 +48,7 @@ func WithRequestBodyStruct(mime string, description string, target interface{})
 			Content: map[string]*v310.MediaTypeObject{
 				mime: {Schema: s},
 			},
+			Required: true,
 		})
 
 		return rw
@@ -0,0 +1,317 @@
+package echopen
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	MIMEApplicationYAML = "application/yaml"
+
+	// routeContextKey holds the *RouteWrapper for the matched route, set by the validation middleware
+	routeContextKey = "echopen.route"
+)
+
+// respondMediaTypes are the media types Respond can produce when the response declares no content, in order of preference
+var respondMediaTypes = []string{echo.MIMEApplicationJSON, echo.MIMEApplicationXML, MIMEApplicationYAML, echo.MIMEApplicationMsgpack}
+
+// Respond sends the value in the media type which best matches the Accept header, out of those declared for the response code.
+// JSON, XML, YAML, and MessagePack are supported, with XML output following the xml objects of the response schema.
+// Returns ErrNotAcceptable if none of the declared media types are accepted.
+func Respond(c echo.Context, code int, value interface{}) error {
+	var content map[string]*v310.MediaTypeObject
+	var components *v310.Components
+
+	if r, ok := c.Get(routeContextKey).(*RouteWrapper); ok {
+		content = r.responseContent(code)
+		components = r.API.Spec.Components
+	}
+
+	// Offer the declared media types which can be encoded, preferring JSON when any type is accepted
+	offers := []string{}
+	if len(content) == 0 {
+		offers = respondMediaTypes
+	} else {
+		for mt := range content {
+			if responseEncoding(mt) != "" {
+				offers = append(offers, mt)
+			}
+		}
+		sort.Slice(offers, func(i, j int) bool {
+			ei, ej := encodingOrder(offers[i]), encodingOrder(offers[j])
+			if ei != ej {
+				return ei < ej
+			}
+			return offers[i] < offers[j]
+		})
+	}
+
+	mt := negotiate(c.Request().Header.Get(echo.HeaderAccept), offers)
+	if mt == "" {
+		return ErrNotAcceptable
+	}
+
+	var schema *v310.Ref[v310.Schema]
+	if mto, ok := content[mt]; ok {
+		schema = mto.Schema
+	}
+
+	buf, err := encodeResponse(mt, value, schema, components)
+	if err != nil {
+		return err
+	}
+
+	return c.Blob(code, mt, buf)
+}
+
+// responseContent returns the declared content for a status code, falling back to the range and default responses
+func (r *RouteWrapper) responseContent(code int) map[string]*v310.MediaTypeObject {
+	for _, key := range []string{fmt.Sprint(code), fmt.Sprintf("%dXX", code/100), "default"} {
+		if ref, ok := r.Operation.Responses[key]; ok {
+			if resp, ok := ref.DeRef(r.API.Spec.Components).(*v310.Response); ok && resp != nil {
+				return resp.Content
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// responseEncoding returns the name of the encoding used for a media type, or an empty string if not supported
+func responseEncoding(mt string) string {
+	typ, sub := parseMediaType(mt)
+	switch {
+	case typ == "application" && (sub == "json" || strings.HasSuffix(sub, "+json")):
+		return "json"
+	case (typ == "application" || typ == "text") && (sub == "xml" || strings.HasSuffix(sub, "+xml")):
+		return "xml"
+	case (typ == "application" || typ == "text") && (sub == "yaml" || sub == "x-yaml"):
+		return "yaml"
+	case typ == "application" && (sub == "msgpack" || sub == "x-msgpack" || sub == "vnd.msgpack"):
+		return "msgpack"
+	default:
+		return ""
+	}
+}
+
+func encodingOrder(mt string) int {
+	switch responseEncoding(mt) {
+	case "json":
+		return 0
+	case "xml":
+		return 1
+	case "yaml":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// encodeResponse encodes a value for the given media type, using the schema to drive XML output if available
+func encodeResponse(mt string, value interface{}, schema *v310.Ref[v310.Schema], c *v310.Components) ([]byte, error) {
+	encoding := responseEncoding(mt)
+	if encoding == "json" {
+		return json.Marshal(value)
+	} else if encoding == "xml" && schema == nil {
+		// No schema, so rely on the xml struct tags
+		buf, err := xml.Marshal(value)
+		return append([]byte(xml.Header), buf...), err
+	}
+
+	// Other encodings follow the JSON representation of the value
+	g, err := toGeneric(value)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	switch encoding {
+	case "xml":
+		buf.WriteString(xml.Header)
+		err = encodeXMLElement(buf, xmlRootName(schema, c), g, schema, c)
+	case "yaml":
+		err = yaml.NewEncoder(buf).Encode(g)
+	case "msgpack":
+		err = encodeMsgpack(buf, g)
+	}
+	return buf.Bytes(), err
+}
+
+// toGeneric converts a value to maps, slices, and scalars via its JSON encoding.
+// Integers are returned as int64, and other numbers as float64.
+func toGeneric(value interface{}) (interface{}, error) {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(buf))
+	d.UseNumber()
+	var g interface{}
+	if err := d.Decode(&g); err != nil {
+		return nil, err
+	}
+	return normaliseNumbers(g), nil
+}
+
+func normaliseNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, _ := val.Float64()
+		return f
+	case []interface{}:
+		for i := range val {
+			val[i] = normaliseNumbers(val[i])
+		}
+	case map[string]interface{}:
+		for k := range val {
+			val[k] = normaliseNumbers(val[k])
+		}
+	}
+	return v
+}
+
+// xmlRootName names the root element after the schema xml name, or the referenced component name
+func xmlRootName(schema *v310.Ref[v310.Schema], c *v310.Components) string {
+	if s, ok := schema.DeRef(c).(*v310.Schema); ok && s != nil && s.XML != nil && s.XML.Name != "" {
+		return s.XML.Name
+	} else if schema.Ref != "" {
+		return schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+	}
+	return "response"
+}
+
+// encodeXMLElement writes a generic value as an element, applying the xml object of its schema.
+// Object properties are written as child elements (or attributes), and arrays as repeated elements, optionally wrapped.
+func encodeXMLElement(buf *bytes.Buffer, name string, v interface{}, ref *v310.Ref[v310.Schema], c *v310.Components) error {
+	var s *v310.Schema
+	if ref != nil {
+		s, _ = ref.DeRef(c).(*v310.Schema)
+	}
+
+	attrs := []string{}
+	if s != nil && s.XML != nil {
+		if s.XML.Name != "" {
+			name = s.XML.Name
+		}
+		if s.XML.Namespace != "" {
+			if s.XML.Prefix != "" {
+				attrs = append(attrs, fmt.Sprintf(`xmlns:%s="%s"`, s.XML.Prefix, xmlEscape(s.XML.Namespace)))
+			} else {
+				attrs = append(attrs, fmt.Sprintf(`xmlns="%s"`, xmlEscape(s.XML.Namespace)))
+			}
+		}
+		if s.XML.Prefix != "" {
+			name = s.XML.Prefix + ":" + name
+		}
+	}
+
+	switch val := v.(type) {
+	case []interface{}:
+		var items *v310.Ref[v310.Schema]
+		if s != nil {
+			items = s.Items
+		}
+
+		wrapped := s != nil && s.XML != nil && s.XML.Wrapped
+		if wrapped {
+			writeXMLStart(buf, name, attrs)
+		}
+		for _, item := range val {
+			if err := encodeXMLElement(buf, name, item, items, c); err != nil {
+				return err
+			}
+		}
+		if wrapped {
+			buf.WriteString("</" + name + ">")
+		}
+		return nil
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		// Properties marked as attributes are written in the start tag
+		children := []string{}
+		for _, k := range keys {
+			var ps *v310.Schema
+			if prop := xmlPropertySchema(s, k, c); prop != nil {
+				ps, _ = prop.DeRef(c).(*v310.Schema)
+			}
+			if ps != nil && ps.XML != nil && ps.XML.Attribute {
+				attrName := k
+				if ps.XML.Name != "" {
+					attrName = ps.XML.Name
+				}
+				attrs = append(attrs, fmt.Sprintf(`%s="%s"`, attrName, xmlEscape(fmt.Sprint(val[k]))))
+			} else {
+				children = append(children, k)
+			}
+		}
+
+		writeXMLStart(buf, name, attrs)
+		for _, k := range children {
+			if err := encodeXMLElement(buf, k, val[k], xmlPropertySchema(s, k, c), c); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("</" + name + ">")
+		return nil
+
+	case nil:
+		writeXMLStart(buf, name, attrs)
+		buf.WriteString("</" + name + ">")
+		return nil
+
+	default:
+		writeXMLStart(buf, name, attrs)
+		buf.WriteString(xmlEscape(fmt.Sprint(val)))
+		buf.WriteString("</" + name + ">")
+		return nil
+	}
+}
+
+// xmlPropertySchema finds the schema of a property, including properties of composed allOf schemas
+func xmlPropertySchema(s *v310.Schema, name string, c *v310.Components) *v310.Ref[v310.Schema] {
+	if s == nil {
+		return nil
+	}
+	if prop, ok := s.Properties[name]; ok {
+		return prop
+	}
+	for _, ref := range s.AllOf {
+		sub, _ := ref.DeRef(c).(*v310.Schema)
+		if prop := xmlPropertySchema(sub, name, c); prop != nil {
+			return prop
+		}
+	}
+	return nil
+}
+
+func writeXMLStart(buf *bytes.Buffer, name string, attrs []string) {
+	buf.WriteString("<" + name)
+	for _, a := range attrs {
+		buf.WriteString(" " + a)
+	}
+	buf.WriteString(">")
+}
+
+func xmlEscape(s string) string {
+	buf := &bytes.Buffer{}
+	_ = xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
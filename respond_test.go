@@ -0,0 +1,128 @@
+package echopen_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/richjyoung/echopen"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+	"github.com/stretchr/testify/assert"
+)
+
+type NegotiatedPet struct {
+	ID   int      `json:"id" xml:"id,attr"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func TestRespond(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+
+	pet := NegotiatedPet{ID: 1, Name: "Rex & Co", Tags: []string{"a"}}
+
+	api.GET("/pet", func(c echo.Context) error {
+		return echopen.Respond(c, http.StatusOK, pet)
+	}, echopen.WithResponseStructConfig("200", &echopen.ResponseStructConfig{
+		Description: "Pet",
+		Target:      NegotiatedPet{},
+		JSON:        true,
+		XML:         true,
+		YAML:        true,
+		MessagePack: true,
+	}))
+
+	api.GET("/pets", func(c echo.Context) error {
+		return echopen.Respond(c, http.StatusOK, []NegotiatedPet{pet, pet})
+	}, echopen.WithResponse("200", &v310.Response{
+		Description: "Pets",
+		Content: map[string]*v310.MediaTypeObject{
+			echo.MIMEApplicationXML: {
+				Schema: &v310.Ref[v310.Schema]{Value: &v310.Schema{
+					Type: "array",
+					XML:  &v310.XML{Name: "pets", Wrapped: true},
+					Items: &v310.Ref[v310.Schema]{Value: &v310.Schema{
+						AllOf: []*v310.Ref[v310.Schema]{{Ref: "#/components/schemas/NegotiatedPet"}},
+						XML:   &v310.XML{Name: "pet"},
+					}},
+				}},
+			},
+		},
+	}))
+
+	api.GET("/undeclared", func(c echo.Context) error {
+		return echopen.Respond(c, http.StatusOK, map[string]interface{}{"ok": true})
+	})
+
+	type tcd struct {
+		path        string
+		accept      string
+		code        int
+		contentType string
+		body        string
+	}
+
+	tcs := map[string]tcd{
+		"no_accept": {
+			path: "/pet", code: http.StatusOK, contentType: echo.MIMEApplicationJSON,
+			body: `{"id":1,"name":"Rex \u0026 Co","tags":["a"]}`,
+		},
+		"any": {
+			path: "/pet", accept: "*/*", code: http.StatusOK, contentType: echo.MIMEApplicationJSON,
+			body: `{"id":1,"name":"Rex \u0026 Co","tags":["a"]}`,
+		},
+		"xml": {
+			path: "/pet", accept: "application/xml", code: http.StatusOK, contentType: echo.MIMEApplicationXML,
+			body: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+				`<NegotiatedPet id="1"><name>Rex &amp; Co</name><tags>a</tags></NegotiatedPet>`,
+		},
+		"yaml": {
+			path: "/pet", accept: "application/yaml", code: http.StatusOK, contentType: echopen.MIMEApplicationYAML,
+			body: "id: 1\nname: Rex & Co\ntags:\n    - a\n",
+		},
+		"msgpack": {
+			path: "/pet", accept: "application/msgpack", code: http.StatusOK, contentType: echo.MIMEApplicationMsgpack,
+			body: "\x83\xa2id\x01\xa4name\xa8Rex & Co\xa4tags\x91\xa1a",
+		},
+		"quality": {
+			path: "/pet", accept: "application/json;q=0.5, application/*;q=0.8, application/yaml;q=0.1", code: http.StatusOK, contentType: echo.MIMEApplicationXML,
+			body: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+				`<NegotiatedPet id="1"><name>Rex &amp; Co</name><tags>a</tags></NegotiatedPet>`,
+		},
+		"not_acceptable": {
+			path: "/pet", accept: "text/html", code: http.StatusNotAcceptable, contentType: echo.MIMEApplicationJSON,
+			body: `{"message":"Not Acceptable"}` + "\n",
+		},
+		"excluded": {
+			path: "/pets", accept: "application/xml;q=0, */*", code: http.StatusNotAcceptable, contentType: echo.MIMEApplicationJSON,
+			body: `{"message":"Not Acceptable"}` + "\n",
+		},
+		"wrapped": {
+			path: "/pets", accept: "application/xml", code: http.StatusOK, contentType: echo.MIMEApplicationXML,
+			body: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+				`<pets><pet id="1"><name>Rex &amp; Co</name><tags>a</tags></pet><pet id="1"><name>Rex &amp; Co</name><tags>a</tags></pet></pets>`,
+		},
+		"undeclared": {
+			path: "/undeclared", accept: "application/yaml", code: http.StatusOK, contentType: echopen.MIMEApplicationYAML,
+			body: "ok: true\n",
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tc.accept)
+			}
+			res := httptest.NewRecorder()
+			api.Engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.code, res.Code)
+			assert.Contains(t, res.Header().Get(echo.HeaderContentType), tc.contentType)
+			assert.Equal(t, tc.body, res.Body.String())
+		})
+	}
+
+	schema := api.Spec.Components.Schemas["NegotiatedPet"]
+	assert.Equal(t, &v310.XML{Attribute: true, Name: "id"}, schema.Properties["id"].Value.XML)
+}
@@ -12,6 +12,9 @@ type ResponseStructConfig struct {
 	Description string
 	Target      interface{}
 	JSON        bool
+	XML         bool
+	YAML        bool
+	MessagePack bool
 }
 
 func WithResponse(code string, resp *v310.Response) RouteConfigFunc {
@@ -68,6 +71,15 @@ func WithResponseStructConfig(code string, config *ResponseStructConfig) RouteCo
 		if config.JSON {
 			content[echo.MIMEApplicationJSON] = &v310.MediaTypeObject{Schema: schema}
 		}
+		if config.XML {
+			content[echo.MIMEApplicationXML] = &v310.MediaTypeObject{Schema: schema}
+		}
+		if config.YAML {
+			content[MIMEApplicationYAML] = &v310.MediaTypeObject{Schema: schema}
+		}
+		if config.MessagePack {
+			content[echo.MIMEApplicationMsgpack] = &v310.MediaTypeObject{Schema: schema}
+		}
 
 		rw.Operation.AddResponse(code, &v310.Response{
 			Description: config.Description,
@@ -0,0 +1,121 @@
+package echopen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+)
+
+type ResponseValidationMode int
+
+const (
+	// ResponseValidationDisabled sends responses without checking them (default)
+	ResponseValidationDisabled ResponseValidationMode = iota
+	// ResponseValidationLog logs responses which do not match the spec, then sends them unchanged
+	ResponseValidationLog
+	// ResponseValidationError replaces responses which do not match the spec with a 500 error
+	ResponseValidationError
+)
+
+// responseBuffer captures the status and body written by a handler, so it can be checked before being sent.
+// Headers are written directly to the underlying response.
+type responseBuffer struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (b *responseBuffer) WriteHeader(code int) {
+	b.status = code
+}
+
+func (b *responseBuffer) Write(buf []byte) (int, error) {
+	return b.body.Write(buf)
+}
+
+func (b *responseBuffer) Flush() {}
+
+// responseValidationMiddleware checks responses written by the handler against the operation responses.
+// Errors returned by the handler are passed through to the error handler unchecked.
+func (r *RouteWrapper) responseValidationMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			res := c.Response()
+			writer := res.Writer
+			buf := &responseBuffer{ResponseWriter: writer, status: http.StatusOK}
+			res.Writer = buf
+
+			err := next(c)
+			res.Writer = writer
+			if err != nil || !res.Committed {
+				return err
+			}
+
+			if verr := r.validateResponse(buf.status, res.Header(), buf.body.Bytes()); verr != nil {
+				if r.API.Config.ValidateResponses == ResponseValidationError {
+					// Discard the invalid response so the error handler can send its own
+					res.Committed = false
+					res.Size = 0
+					res.Header().Del(echo.HeaderContentType)
+					res.Header().Del(echo.HeaderContentLength)
+					return verr
+				}
+				c.Logger().Errorf("%s %s: %s", r.Method, r.Path, verr)
+			}
+
+			writer.WriteHeader(buf.status)
+			_, err = writer.Write(buf.body.Bytes())
+			return err
+		}
+	}
+}
+
+// validateResponse checks the status code, content type, and JSON body of a response against the operation.
+func (r *RouteWrapper) validateResponse(status int, header http.Header, body []byte) error {
+	ref, ok := r.Operation.Responses[fmt.Sprint(status)]
+	if !ok {
+		ref, ok = r.Operation.Responses[fmt.Sprintf("%dXX", status/100)]
+	}
+	if !ok {
+		ref, ok = r.Operation.Responses["default"]
+	}
+	if !ok {
+		return fmt.Errorf("%w: status %d not declared", ErrResponseInvalid, status)
+	}
+
+	resp, _ := ref.DeRef(r.API.Spec.Components).(*v310.Response)
+	if resp == nil || len(resp.Content) == 0 {
+		if len(body) > 0 {
+			return fmt.Errorf("%w: status %d declares no content", ErrResponseInvalid, status)
+		}
+		return nil
+	}
+
+	mt := header.Get(echo.HeaderContentType)
+	key, ok := matchMediaType(mt, resp.Content)
+	if !ok {
+		return fmt.Errorf("%w: content type %s not declared for status %d", ErrResponseInvalid, mt, status)
+	}
+
+	content := resp.Content[key]
+
+	if content.Schema == nil || !isJSONMediaType(mt) {
+		return nil
+	}
+
+	var val interface{}
+	if err := json.Unmarshal(body, &val); err != nil {
+		return fmt.Errorf("%w: %s", ErrResponseInvalid, err)
+	}
+
+	schema, _ := content.Schema.DeRef(r.API.Spec.Components).(*v310.Schema)
+	if err := schema.ValidateJSON(val, r.API.Spec.Components); err != nil {
+		return fmt.Errorf("%w: %s", ErrResponseInvalid, err)
+	}
+
+	return nil
+}
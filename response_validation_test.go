@@ -0,0 +1,140 @@
+package echopen_test
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/richjyoung/echopen"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+	"github.com/stretchr/testify/assert"
+)
+
+type ValidatedPet struct {
+	ID   int      `json:"id"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+func TestResponseValidation(t *testing.T) {
+	api := echopen.New("Test", "1.0.0", echopen.WithResponseValidation(echopen.ResponseValidationError))
+
+	route := func(path string, handler echo.HandlerFunc) {
+		api.GET(
+			path,
+			handler,
+			echopen.WithResponseStruct("200", "Pet", ValidatedPet{}),
+			echopen.WithResponseDescription("404", "Not found"),
+			echopen.WithResponseFile("default", "Error", echo.MIMETextPlain),
+		)
+	}
+
+	route("/valid", func(c echo.Context) error {
+		return c.JSON(200, ValidatedPet{ID: 1, Name: "Rex", Tags: []string{"dog"}})
+	})
+	route("/missing", func(c echo.Context) error {
+		return c.JSON(200, map[string]interface{}{"id": 1})
+	})
+	route("/type", func(c echo.Context) error {
+		return c.JSON(200, map[string]interface{}{"id": 1.5, "name": "Rex"})
+	})
+	route("/items", func(c echo.Context) error {
+		return c.JSON(200, map[string]interface{}{"id": 1, "name": "Rex", "tags": []int{1}})
+	})
+	route("/content_type", func(c echo.Context) error {
+		return c.XML(200, ValidatedPet{ID: 1, Name: "Rex"})
+	})
+	route("/no_content", func(c echo.Context) error {
+		return c.NoContent(404)
+	})
+	route("/unexpected_content", func(c echo.Context) error {
+		return c.String(404, "not found")
+	})
+	route("/default", func(c echo.Context) error {
+		return c.String(503, "unavailable")
+	})
+	route("/error", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusTeapot)
+	})
+
+	type tcd struct {
+		Path string
+		Code int
+	}
+
+	defs := []tcd{
+		{"/valid", 200},
+		{"/missing", 500},
+		{"/type", 500},
+		{"/items", 500},
+		{"/content_type", 500},
+		{"/no_content", 404},
+		{"/unexpected_content", 500},
+		{"/default", 503},
+		{"/error", 418},
+	}
+
+	for _, tc := range defs {
+		t.Run(tc.Path, func(t *testing.T) {
+			_, res := executeRequest(api, http.MethodGet, tc.Path, nil)
+			assert.Equal(t, tc.Code, res.Result().StatusCode)
+			if tc.Code == 500 {
+				assert.Equal(t, echo.MIMEApplicationJSONCharsetUTF8, res.Header().Get(echo.HeaderContentType))
+			}
+		})
+	}
+}
+
+func TestResponseValidationLog(t *testing.T) {
+	api := echopen.New("Test", "1.0.0", echopen.WithResponseValidation(echopen.ResponseValidationLog))
+	logs := &bytes.Buffer{}
+	api.Engine.Logger.SetOutput(logs)
+
+	api.GET("/", func(c echo.Context) error {
+		return c.JSON(201, ValidatedPet{ID: 1, Name: "Rex"})
+	}, echopen.WithResponseStruct("200", "Pet", ValidatedPet{}))
+
+	_, res := executeRequest(api, http.MethodGet, "/", nil)
+	assert.Equal(t, 201, res.Result().StatusCode)
+	assert.JSONEq(t, `{"id":1,"name":"Rex"}`, res.Body.String())
+	assert.Contains(t, logs.String(), "status 201 not declared")
+}
+
+func TestResponseValidationNil(t *testing.T) {
+	type Owner struct {
+		Name string `json:"name"`
+	}
+
+	type NillablePet struct {
+		Name     string            `json:"name"`
+		Tags     []string          `json:"tags"`
+		Nickname *string           `json:"nickname"`
+		Labels   map[string]string `json:"labels"`
+		Owner    *Owner            `json:"owner"`
+	}
+
+	api := echopen.New("Test", "1.0.0", echopen.WithResponseValidation(echopen.ResponseValidationError))
+	api.GET("/nil", func(c echo.Context) error {
+		return c.JSON(200, NillablePet{Name: "Rex"})
+	}, echopen.WithResponseStruct("200", "Pet", NillablePet{}))
+	api.GET("/set", func(c echo.Context) error {
+		nickname := "Rexy"
+		return c.JSON(200, NillablePet{Name: "Rex", Tags: []string{"dog"}, Nickname: &nickname, Labels: map[string]string{"a": "b"}, Owner: &Owner{Name: "Jo"}})
+	}, echopen.WithResponseStruct("200", "Pet", NillablePet{}))
+	api.GET("/invalid", func(c echo.Context) error {
+		return c.JSON(200, map[string]interface{}{"name": "Rex", "tags": nil, "nickname": 1, "labels": nil, "owner": nil})
+	}, echopen.WithResponseStruct("200", "Pet", NillablePet{}))
+
+	// Nil fields without omitempty are documented as nullable
+	pet := api.Spec.Components.Schemas["NillablePet"]
+	assert.Equal(t, []v310.SchemaType{v310.ArraySchemaType, v310.NullSchemaType}, pet.Properties["tags"].Value.Types)
+	assert.Equal(t, []v310.SchemaType{v310.StringSchemaType, v310.NullSchemaType}, pet.Properties["nickname"].Value.Types)
+	assert.Equal(t, "#/components/schemas/Owner", pet.Properties["owner"].Value.AnyOf[0].Ref)
+	assert.Equal(t, v310.StringSchemaType, pet.Properties["name"].Value.Type)
+
+	for path, code := range map[string]int{"/nil": 200, "/set": 200, "/invalid": 500} {
+		_, res := executeRequest(api, http.MethodGet, path, nil)
+		assert.Equal(t, code, res.Result().StatusCode, path)
+	}
+}
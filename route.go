@@ -1,11 +1,17 @@
 package echopen
 
 import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"reflect"
+	"strings"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
 )
@@ -13,108 +19,195 @@ import (
 type RouteWrapper struct {
 	API               *APIWrapper
 	Group             *GroupWrapper
+	Method            string
+	Path              string
 	Operation         *v310.Operation
 	PathItem          *v310.PathItem
 	Handler           echo.HandlerFunc
 	Middlewares       []echo.MiddlewareFunc
 	Route             *echo.Route
+	PathSchema        *v310.Schema
+	HeaderSchema      *v310.Schema
+	CookieSchema      *v310.Schema
 	QuerySchema       *v310.Schema
 	FormSchema        *v310.Schema
 	RequestBodySchema map[string]*v310.Schema
+	ContextTypes      map[string]reflect.Type
 }
 
 // Operation validation middleware that is applied to all routes
 func (r *RouteWrapper) middleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
-		val := validator.New(validator.WithRequiredStructEnabled())
+		queryVal := newValidator("query")
+		bodyVal := newValidator("json")
+		formVal := newValidator("form")
+		registerFileValidations(formVal)
+
+		var formLimit int64
+		if r.FormSchema != nil && r.FormSchema.SourceType != nil {
+			formLimit = formBodyLimit(r.FormSchema.SourceType)
+		}
+		pathVal := newValidator("path")
+		headerVal := newValidator("header")
+		cookieVal := newValidator("cookie")
+
+		// Parameter structs are bound through copies of their types with echo binder tags
+		var pathBindType, headerBindType, cookieBindType reflect.Type
+		if r.PathSchema != nil && r.PathSchema.SourceType != nil {
+			pathBindType = paramBindType(r.PathSchema.SourceType, "path", "param")
+		}
+		if r.HeaderSchema != nil && r.HeaderSchema.SourceType != nil {
+			headerBindType = paramBindType(r.HeaderSchema.SourceType, "header", "header")
+		}
+		if r.CookieSchema != nil && r.CookieSchema.SourceType != nil {
+			cookieBindType = paramBindType(r.CookieSchema.SourceType, "cookie", "form")
+		}
 
 		return func(c echo.Context) error {
+			// Make the route available to Respond
+			c.Set(routeContextKey, r)
+
 			// --------------------------------------------------------------------------------
 			// Check security requirements have been met, if specified
 			// --------------------------------------------------------------------------------
-			securityReqsMet := len(r.Operation.Security) == 0
-
-			for _, req := range r.Operation.Security {
-				if len(*req) == 0 {
-					// Empty object makes all requirements optional
-					securityReqsMet = true
-				} else {
-					for name, scopes := range *req {
-						scheme := r.API.Spec.GetComponents().GetSecurityScheme(name)
-						if scheme == nil {
-							// Scheme existence is checked at the point the requirement is added
-							continue
-						}
-
-						switch scheme.In {
-						case "header":
-							val, ok := c.Request().Header[http.CanonicalHeaderKey(scheme.Name)]
-							if ok && len(val) > 0 {
-								c.Set(fmt.Sprintf("security.%s", name), val[0])
-								c.Set(fmt.Sprintf("security.%s.scopes", name), scopes)
-								securityReqsMet = true
-							}
-						default:
-							panic("not implemented")
-						}
-					}
-				}
+			securityReqs := r.Operation.Security
+			if securityReqs == nil {
+				// Fall back to the top level requirements if the operation does not override them, where an empty list opts out
+				securityReqs = r.API.Spec.Security
 			}
-
-			if !securityReqsMet {
-				return ErrSecurityRequirementsNotMet
+			if err := r.API.checkSecurityRequirements(c, securityReqs); err != nil {
+				return err
 			}
 
 			// --------------------------------------------------------------------------------
 			// Extract path, header, and cookie parameters (query dealt with as a struct)
 			// --------------------------------------------------------------------------------
-			for _, ref := range r.Operation.Parameters {
-				param := ref.DeRef(r.API.Spec.Components).(*v310.Parameter)
-
+			for _, param := range r.parameters() {
 				switch param.In {
 				case "path":
 					v := c.Param(param.Name)
 					if v == "" {
-						return ErrRequiredParameterMissing
+						return &ParameterError{Name: param.Name, In: param.In, Reason: ParameterMissing}
 					}
 					val := param.Schema.FromString(v)
 					if val == nil {
-						return ErrRequiredParameterMissing
+						return &ParameterError{Name: param.Name, In: param.In, Reason: ParameterInvalid}
+					}
+					if err := r.validateParameter(param, val); err != nil {
+						return err
 					}
 					c.Set(fmt.Sprintf("path.%s", param.Name), val)
 
 				case "header":
 					v := c.Request().Header[param.Name]
 					if len(v) == 0 {
-						return ErrRequiredParameterMissing
-					}
-					if param.Schema.Type == "array" {
-						hdrs := []interface{}{}
-						for _, h := range v {
-							hdrs = append(hdrs, param.Schema.Items.DeRef(r.API.Spec.Components).(*v310.Schema).FromString(h))
+						if param.Required {
+							return &ParameterError{Name: param.Name, In: param.In, Reason: ParameterMissing}
 						}
-						c.Set(fmt.Sprintf("header.%s", param.Name), hdrs)
-					} else {
-						val := param.Schema.FromString(v[0])
-						if val == nil {
-							return ErrRequiredParameterMissing
+						continue
+					}
+					val, err := r.multiValueParameter(param, v)
+					if err != nil {
+						return err
+					}
+					c.Set(fmt.Sprintf("header.%s", param.Name), val)
+
+				case "query":
+					// Query and GET form structs are bound and validated as a whole below
+					if r.QuerySchema != nil || (r.FormSchema != nil && (r.Method == http.MethodGet || r.Method == http.MethodDelete)) {
+						continue
+					}
+					v := c.QueryParams()[param.Name]
+					if len(v) == 0 {
+						if param.Required {
+							return &ParameterError{Name: param.Name, In: param.In, Reason: ParameterMissing}
 						}
-						c.Set(fmt.Sprintf("header.%s", param.Name), val)
+						continue
+					}
+					val, err := r.multiValueParameter(param, v)
+					if err != nil {
+						return err
 					}
+					c.Set(fmt.Sprintf("query.%s", param.Name), val)
 
 				case "cookie":
 					v, err := c.Cookie(param.Name)
-					if err != nil && param.Required {
-						return ErrRequiredParameterMissing
+					if err != nil {
+						if param.Required {
+							return &ParameterError{Name: param.Name, In: param.In, Reason: ParameterMissing}
+						}
+						continue
 					}
 					val := param.Schema.FromString(v.Value)
 					if val == nil {
-						return ErrRequiredParameterMissing
+						return &ParameterError{Name: param.Name, In: param.In, Reason: ParameterInvalid}
+					}
+					if err := r.validateParameter(param, val); err != nil {
+						return err
 					}
 					c.Set(fmt.Sprintf("cookie.%s", param.Name), val)
 				}
 			}
 
+			// --------------------------------------------------------------------------------
+			// Extract path, header, and cookie structs
+			// --------------------------------------------------------------------------------
+			if pathBindType != nil {
+				v, err := bindParamStruct(pathBindType, r.PathSchema.SourceType, func(b interface{}) error {
+					return (&echo.DefaultBinder{}).BindPathParams(c, b)
+				})
+				if err != nil {
+					return err
+				}
+
+				// Validate the bound struct
+				if err := pathVal.StructCtx(c.Request().Context(), v); err != nil {
+					return validationError("path", err)
+				}
+
+				// Add to context
+				c.Set("path", v)
+			}
+
+			if headerBindType != nil {
+				v, err := bindParamStruct(headerBindType, r.HeaderSchema.SourceType, func(b interface{}) error {
+					return (&echo.DefaultBinder{}).BindHeaders(c, b)
+				})
+				if err != nil {
+					return err
+				}
+
+				// Validate the bound struct
+				if err := headerVal.StructCtx(c.Request().Context(), v); err != nil {
+					return validationError("header", err)
+				}
+
+				// Add to context
+				c.Set("header", v)
+			}
+
+			if cookieBindType != nil {
+				v, err := bindParamStruct(cookieBindType, r.CookieSchema.SourceType, func(b interface{}) error {
+					// Echo has no cookie binder, so present the cookies as form values
+					vals := url.Values{}
+					for _, cookie := range c.Cookies() {
+						vals.Add(cookie.Name, cookie.Value)
+					}
+					return bindFormValues(c, b, vals)
+				})
+				if err != nil {
+					return err
+				}
+
+				// Validate the bound struct
+				if err := cookieVal.StructCtx(c.Request().Context(), v); err != nil {
+					return validationError("cookie", err)
+				}
+
+				// Add to context
+				c.Set("cookie", v)
+			}
+
 			// --------------------------------------------------------------------------------
 			// Extract query
 			// --------------------------------------------------------------------------------
@@ -129,8 +222,8 @@ func (r *RouteWrapper) middleware() echo.MiddlewareFunc {
 				}
 
 				// Validate the bound struct
-				if err := val.StructCtx(c.Request().Context(), v); err != nil {
-					return err
+				if err := queryVal.StructCtx(c.Request().Context(), v); err != nil {
+					return validationError("query", err)
 				}
 
 				// Add to context
@@ -138,38 +231,303 @@ func (r *RouteWrapper) middleware() echo.MiddlewareFunc {
 			}
 
 			// --------------------------------------------------------------------------------
-			// Extract request body
+			// Extract form
 			// --------------------------------------------------------------------------------
-			if len(r.RequestBodySchema) != 0 {
-				cts := c.Request().Header["Content-Type"]
-				mime := ""
-				if len(cts) == 1 {
-					mime = cts[0]
-					if schema, ok := r.RequestBodySchema[mime]; ok {
-						if schema.SourceType != nil {
-							// Create a new struct of the given type
-							v := reflect.New(schema.SourceType).Interface()
-
-							// Bind the struct to the body
-							if err := (&echo.DefaultBinder{}).BindBody(c, v); err != nil {
-								return err
-							}
-
-							// Validate the bound struct
-							if err := val.StructCtx(c.Request().Context(), v); err != nil {
-								return err
-							}
-
-							// Add to context
-							c.Set("body", v)
-						}
+			formBound := false
+			if r.FormSchema != nil && r.FormSchema.SourceType != nil {
+				in := "body"
+				bind := true
+
+				// Create a new struct of the given type
+				v := reflect.New(r.FormSchema.SourceType).Interface()
+
+				if r.Method == http.MethodGet || r.Method == http.MethodDelete {
+					// Bind the struct to the query params
+					in = "query"
+					if err := bindFormValues(c, v, c.QueryParams()); err != nil {
+						return err
 					}
 				} else {
+					ct := c.Request().Header.Get(echo.HeaderContentType)
+					mt, ok := matchMediaType(ct, formMediaTypes)
+					if !ok {
+						// Other content types may still match the request body
+						if len(r.RequestBodySchema) == 0 {
+							return ErrContentTypeNotSupported
+						}
+						bind = false
+					} else if err := bindFormBody(c, v, mt, formLimit); err != nil {
+						return err
+					} else if mt == echo.MIMEMultipartForm {
+						// Bind any uploaded files
+						if err := bindFormFiles(c, v); err != nil {
+							return err
+						}
+					}
+				}
+
+				if bind {
+					// Validate the bound struct
+					if err := formVal.StructCtx(c.Request().Context(), v); err != nil {
+						return validationError(in, err)
+					}
+
+					// Add to context
+					c.Set("form", v)
+					formBound = in == "body"
+				}
+			}
+
+			// --------------------------------------------------------------------------------
+			// Extract request body
+			// --------------------------------------------------------------------------------
+			// Optional bodies may be omitted entirely, otherwise the content type must match one declared
+			ct := c.Request().Header.Get(echo.HeaderContentType)
+			if len(r.RequestBodySchema) != 0 && !formBound && (ct != "" || r.requestBodyRequired()) {
+				key, ok := matchMediaType(ct, r.RequestBodySchema)
+				if !ok {
 					return ErrContentTypeNotSupported
 				}
+
+				if schema := r.RequestBodySchema[key]; schema.SourceType != nil {
+					// Create a new struct of the given type
+					v := reflect.New(schema.SourceType).Interface()
+
+					// Bind the struct to the body
+					if err := bindBody(c, v, ct); err != nil {
+						return err
+					}
+
+					// Validate the bound struct
+					if err := bodyVal.StructCtx(c.Request().Context(), v); err != nil {
+						return validationError("body", err)
+					}
+
+					// Add to context
+					c.Set("body", v)
+				} else if isJSONMediaType(ct) {
+					// No struct to bind, so validate the raw body against the schema
+					v, err := r.validateJSONBody(c, schema)
+					if err != nil {
+						return err
+					}
+
+					// Add to context
+					c.Set("body", v)
+				}
 			}
 
 			return next(c)
 		}
 	}
 }
+
+// parameters returns the operation parameters, followed by any declared on the path item which the operation does not override
+func (r *RouteWrapper) parameters() []*v310.Parameter {
+	params := []*v310.Parameter{}
+	declared := map[string]bool{}
+	for _, ref := range r.Operation.Parameters {
+		param, ok := ref.DeRef(r.API.Spec.Components).(*v310.Parameter)
+		if !ok || param == nil {
+			continue
+		}
+		declared[string(param.In)+"."+param.Name] = true
+		params = append(params, param)
+	}
+
+	if r.PathItem != nil {
+		for _, ref := range r.PathItem.Parameters {
+			param, ok := ref.DeRef(r.API.Spec.Components).(*v310.Parameter)
+			if ok && param != nil && !declared[string(param.In)+"."+param.Name] {
+				params = append(params, param)
+			}
+		}
+	}
+	return params
+}
+
+// multiValueParameter converts a parameter which may be repeated, such as a header or query parameter.
+// Array parameters convert every value, otherwise only the first is used.
+func (r *RouteWrapper) multiValueParameter(param *v310.Parameter, v []string) (interface{}, error) {
+	if param.Schema != nil && param.Schema.Type == "array" {
+		// Items without a schema are kept as strings
+		var items *v310.Schema
+		if param.Schema.Items != nil {
+			items, _ = param.Schema.Items.DeRef(r.API.Spec.Components).(*v310.Schema)
+		}
+
+		vals := []interface{}{}
+		for _, s := range v {
+			val := items.FromString(s)
+			if val == nil {
+				return nil, &ParameterError{Name: param.Name, In: param.In, Reason: ParameterInvalid}
+			}
+			vals = append(vals, val)
+		}
+		if err := r.validateParameter(param, vals); err != nil {
+			return nil, err
+		}
+		return vals, nil
+	}
+
+	val := param.Schema.FromString(v[0])
+	if val == nil {
+		return nil, &ParameterError{Name: param.Name, In: param.In, Reason: ParameterInvalid}
+	}
+	if err := r.validateParameter(param, val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// requestBodyRequired reports whether the operation request body is marked as required
+func (r *RouteWrapper) requestBodyRequired() bool {
+	if r.Operation.RequestBody == nil {
+		return false
+	}
+	rb, ok := r.Operation.RequestBody.DeRef(r.API.Spec.Components).(*v310.RequestBody)
+	return ok && rb != nil && rb.Required
+}
+
+// validateParameter checks a converted parameter value against the constraints in its schema
+func (r *RouteWrapper) validateParameter(param *v310.Parameter, val interface{}) error {
+	err := param.Schema.Validate(val, r.API.Spec.Components)
+
+	var se *v310.SchemaError
+	if errors.As(err, &se) {
+		return &ParameterError{Name: param.Name, In: param.In, Reason: ParameterInvalid, Rule: se.Keyword, Param: se.Param}
+	}
+	return err
+}
+
+// bindFormValues binds values to a struct with form tags.
+// The echo binder only reads form tags from the body, so the values are presented as a form body on a POST copy of the request.
+func bindFormValues(c echo.Context, v interface{}, vals url.Values) error {
+	req := c.Request()
+	defer c.SetRequest(req)
+
+	formReq := req.Clone(req.Context())
+	formReq.Method = http.MethodPost
+	formReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	formReq.URL.RawQuery = ""
+	body := vals.Encode()
+	formReq.ContentLength = int64(len(body))
+	formReq.Body = io.NopCloser(strings.NewReader(body))
+	c.SetRequest(formReq)
+
+	return (&echo.DefaultBinder{}).BindBody(c, v)
+}
+
+// formMediaTypes are the request body media types bound to form structs
+var formMediaTypes = map[string]bool{
+	echo.MIMEApplicationForm: true,
+	echo.MIMEMultipartForm:   true,
+}
+
+// multipartMemory is the maximum size of a multipart body held in memory, matching echo
+const multipartMemory = 32 << 20
+
+// bindFormBody parses a urlencoded or multipart body and binds the values to the struct.
+// The body is parsed by its matched media type, as the echo binder only recognises an exact lower case Content-Type prefix.
+// A limit greater than 0 caps how much of the body is read, with larger bodies rejected with a 413.
+func bindFormBody(c echo.Context, v interface{}, mt string, limit int64) error {
+	req := c.Request()
+
+	if limit > 0 {
+		if req.ContentLength > limit {
+			return echo.ErrStatusRequestEntityTooLarge
+		}
+		req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+	}
+
+	var err error
+	if mt == echo.MIMEMultipartForm {
+		err = req.ParseMultipartForm(multipartMemory)
+	} else {
+		err = req.ParseForm()
+	}
+
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, err.Error()).SetInternal(err)
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+
+	return bindFormValues(c, v, req.Form)
+}
+
+// bindBody decodes the request body into the struct by its media type.
+// The echo binder only recognises an exact lower case Content-Type prefix, which misses types matched case-insensitively or by a declared range.
+func bindBody(c echo.Context, v interface{}, ct string) error {
+	req := c.Request()
+	if req.ContentLength == 0 {
+		return nil
+	}
+
+	typ, sub := parseMediaType(ct)
+	mt := typ + "/" + sub
+
+	var err error
+	switch {
+	case isJSONMediaType(mt):
+		err = c.Echo().JSONSerializer.Deserialize(c, v)
+	case isXMLMediaType(mt):
+		err = xml.NewDecoder(req.Body).Decode(v)
+	case formMediaTypes[mt]:
+		return bindFormBody(c, v, mt, 0)
+	default:
+		return ErrContentTypeNotSupported
+	}
+
+	var he *echo.HTTPError
+	if err != nil && !errors.As(err, &he) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return err
+}
+
+// bindParamStruct binds a value of the bind type, then converts it to the target type, which only differs by tags
+func bindParamStruct(bindType reflect.Type, target reflect.Type, bind func(interface{}) error) (interface{}, error) {
+	b := reflect.New(bindType)
+	if err := bind(b.Interface()); err != nil {
+		return nil, err
+	}
+
+	v := reflect.New(target)
+	v.Elem().Set(b.Elem().Convert(target))
+	return v.Interface(), nil
+}
+
+// validateJSONBody decodes the request body and validates it against a schema with no Go type.
+// The body is restored so the handler can read it again.
+func (r *RouteWrapper) validateJSONBody(c echo.Context, schema *v310.Schema) (interface{}, error) {
+	buf, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, err
+	}
+	c.Request().Body = io.NopCloser(bytes.NewReader(buf))
+
+	var v interface{}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+
+	if err := schema.ValidateJSON(v, r.API.Spec.Components); err != nil {
+		return nil, schemaValidationError("body", err)
+	}
+
+	return v, nil
+}
+
+// isJSONMediaType returns true for application/json and +json media types, ignoring any parameters
+func isJSONMediaType(mt string) bool {
+	typ, sub := parseMediaType(mt)
+	return (typ == "application" && sub == "json") || strings.HasSuffix(sub, "+json")
+}
+
+// isXMLMediaType returns true for application/xml, text/xml, and any +xml suffix
+func isXMLMediaType(mt string) bool {
+	typ, sub := parseMediaType(mt)
+	return ((typ == "application" || typ == "text") && sub == "xml") || strings.HasSuffix(sub, "+xml")
+}
@@ -83,3 +83,21 @@ func WithSecurityRequirement(name string, scopes []string) RouteConfigFunc {
 		return rw
 	}
 }
+
+// WithCombinedSecurityRequirement attaches a requirement to a route where all of the named security schemes must be fulfilled together.
+// Each requirement added to a route is an alternative, so only one requirement needs to be met for the request to proceed
+func WithCombinedSecurityRequirement(req *v310.SecurityRequirement) RouteConfigFunc {
+	return func(rw *RouteWrapper) *RouteWrapper {
+		// Lookup the matching schemes
+		for name := range *req {
+			if rw.API.Spec.GetComponents().GetSecurityScheme(name) == nil {
+				panic("echopen: security scheme not registered")
+			}
+		}
+
+		// Add the requirement to the operation definition
+		rw.Operation.AddSecurityRequirement(req)
+
+		return rw
+	}
+}
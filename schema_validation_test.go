@@ -0,0 +1,76 @@
+package echopen_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/richjyoung/echopen"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestBodySchemaValidation(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+
+	api.Spec.GetComponents().AddSchema("Tag", &v310.Schema{
+		Type:      v310.StringSchemaType,
+		MinLength: echopen.PtrTo(2),
+	})
+
+	schema := &v310.Schema{
+		Type:     v310.ObjectSchemaType,
+		Required: []string{"kind", "name"},
+		Properties: map[string]*v310.Ref[v310.Schema]{
+			"kind": {Value: &v310.Schema{Type: v310.StringSchemaType, Const: "pet"}},
+			"name": {Value: &v310.Schema{Type: v310.StringSchemaType, MaxLength: echopen.PtrTo(10)}},
+			"tags": {Value: &v310.Schema{Type: v310.ArraySchemaType, Items: v310.NewSchemaRef("#/components/schemas/Tag")}},
+			"age": {Value: &v310.Schema{OneOf: []*v310.Ref[v310.Schema]{
+				{Value: &v310.Schema{Type: v310.IntegerSchemaType, Minimum: echopen.PtrTo(0.0)}},
+				{Value: &v310.Schema{Type: v310.StringSchemaType, Enum: []interface{}{"unknown"}}},
+			}}},
+		},
+		AdditionalProperties: &v310.Ref[v310.Schema]{Value: &v310.Schema{Type: v310.BooleanSchemaType}},
+	}
+
+	api.POST("/", func(c echo.Context) error {
+		body := c.Get("body").(map[string]interface{})
+		assert.Equal(t, "pet", body["kind"])
+		return c.NoContent(204)
+	}, echopen.WithRequestBodySchema(echo.MIMEApplicationJSON, schema))
+
+	type tcd struct {
+		Name  string
+		Body  string
+		Code  int
+		Error string
+	}
+
+	defs := []tcd{
+		{"valid", `{"kind":"pet","name":"Rex","tags":["dog"],"age":3,"neutered":true}`, 204, ""},
+		{"age_string", `{"kind":"pet","name":"Rex","age":"unknown"}`, 204, ""},
+		{"required", `{"kind":"pet"}`, 400, `{"name":"name","in":"body","rule":"required","param":"name"}`},
+		{"const", `{"kind":"toy","name":"Rex"}`, 400, `{"name":"kind","in":"body","rule":"const","param":"pet"}`},
+		{"max_length", `{"kind":"pet","name":"Rexxxxxxxxxxx"}`, 400, `{"name":"name","in":"body","rule":"maxLength","param":"10"}`},
+		{"ref", `{"kind":"pet","name":"Rex","tags":["dog","x"]}`, 400, `{"name":"tags.1","in":"body","rule":"minLength","param":"2"}`},
+		{"one_of", `{"kind":"pet","name":"Rex","age":-1}`, 400, `{"name":"age","in":"body","rule":"oneOf"}`},
+		{"additional", `{"kind":"pet","name":"Rex","neutered":"yes"}`, 400, `{"name":"neutered","in":"body","rule":"type","param":"boolean"}`},
+		{"root_type", `[]`, 400, `{"name":"body","in":"body","rule":"type","param":"object"}`},
+		{"malformed", `{`, 400, ""},
+	}
+
+	for _, tc := range defs {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.Body))
+			req.Header.Set("Content-Type", echo.MIMEApplicationJSON)
+			res := httptest.NewRecorder()
+			api.Engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.Code, res.Result().StatusCode)
+			if tc.Error != "" {
+				assert.Contains(t, res.Body.String(), tc.Error)
+			}
+		})
+	}
+}
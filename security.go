@@ -0,0 +1,318 @@
+package echopen
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+)
+
+// BasicCredentials holds the decoded user/password pair from an HTTP basic Authorization header.
+type BasicCredentials struct {
+	Username string
+	Password string
+}
+
+// SecurityVerifierFunc checks a credential extracted for a security scheme, and returns the authenticated principal.
+// The value is the raw credential string; for HTTP basic schemes this is the decoded "user:password" pair.
+// Returning an error wrapping ErrSecurityAccessDenied results in a 403, any other error results in a 401.
+type SecurityVerifierFunc func(c echo.Context, value string, scopes []string) (interface{}, error)
+
+// TokenIntrospector resolves an OAuth2 or OpenID Connect access token into the scopes it grants.
+type TokenIntrospector interface {
+	Introspect(c echo.Context, token string) (*TokenIntrospection, error)
+}
+
+// TokenIntrospection is the result of introspecting an access token.
+type TokenIntrospection struct {
+	Active    bool
+	Scopes    []string
+	Principal interface{}
+}
+
+// InMemoryIntrospector is a TokenIntrospector backed by a fixed map of tokens, intended for testing.
+type InMemoryIntrospector map[string]*TokenIntrospection
+
+func (i InMemoryIntrospector) Introspect(c echo.Context, token string) (*TokenIntrospection, error) {
+	if t, ok := i[token]; ok {
+		return t, nil
+	}
+	return nil, ErrSecurityCredentialsInvalid
+}
+
+// checkSecurityRequirements evaluates each requirement as an alternative (OR), where every scheme within a requirement must be satisfied (AND).
+// Credentials are added to the context for the first satisfied requirement only, which is itself added under the "security" key.
+// Empty requirements (optional security) are only used once none of the others are met, so credentials are still picked up when sent.
+func (w *APIWrapper) checkSecurityRequirements(c echo.Context, reqs []*v310.SecurityRequirement) error {
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	var securityErr error = ErrSecurityRequirementsNotMet
+	var optional *v310.SecurityRequirement
+
+	for _, req := range reqs {
+		if req == nil || len(*req) == 0 {
+			if optional == nil {
+				optional = req
+			}
+			continue
+		}
+
+		// Check schemes in a stable order so verifiers are called and errors reported deterministically
+		names := make([]string, 0, len(*req))
+		for name := range *req {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		creds := map[string]*securityCredential{}
+		var reqErr error
+
+		for _, name := range names {
+			scheme := w.Spec.GetComponents().GetSecurityScheme(name)
+			if scheme == nil {
+				reqErr = ErrSecurityRequirementsNotMet
+				break
+			}
+
+			cred, err := w.checkSecurityScheme(c, name, scheme, (*req)[name])
+			if err != nil {
+				reqErr = err
+				break
+			}
+			creds[name] = cred
+		}
+
+		if reqErr != nil {
+			// Report any rejected credential in preference to a missing one
+			if !errors.Is(reqErr, ErrSecurityRequirementsNotMet) && errors.Is(securityErr, ErrSecurityRequirementsNotMet) {
+				securityErr = reqErr
+			}
+			continue
+		}
+
+		// All schemes satisfied
+		for name, cred := range creds {
+			c.Set(fmt.Sprintf("security.%s", name), cred.Value)
+			c.Set(fmt.Sprintf("security.%s.scopes", name), (*req)[name])
+			if cred.Principal != nil {
+				c.Set(fmt.Sprintf("security.%s.principal", name), cred.Principal)
+			}
+			if cred.Claims != nil {
+				c.Set(fmt.Sprintf("security.%s.claims", name), cred.Claims)
+			}
+		}
+		c.Set("security", req)
+
+		return nil
+	}
+
+	if optional != nil {
+		c.Set("security", optional)
+		return nil
+	}
+
+	return securityErr
+}
+
+// securityCredential holds the extracted and verified credential for a single security scheme.
+type securityCredential struct {
+	Value     interface{}
+	Principal interface{}
+	Claims    *JWTClaims
+}
+
+// checkSecurityScheme extracts the credential for the named scheme, then applies any registered introspector and verifier.
+// Returns ErrSecurityRequirementsNotMet if the credential is not present in the request.
+// Token based schemes with nothing configured to verify them are rejected with ErrSecurityCredentialsInvalid.
+func (w *APIWrapper) checkSecurityScheme(c echo.Context, name string, scheme *v310.SecurityScheme, scopes []string) (*securityCredential, error) {
+	val, ok := extractSecurityValue(c, scheme)
+	if !ok {
+		return nil, ErrSecurityRequirementsNotMet
+	}
+
+	cred := &securityCredential{Value: val}
+
+	str := ""
+	switch v := val.(type) {
+	case string:
+		str = v
+	case BasicCredentials:
+		str = v.Username + ":" + v.Password
+	}
+
+	// Enforce scopes for token based schemes
+	_, hasVerifier := w.securityVerifiers[name]
+	switch {
+	case scheme.Type == v310.OAuth2SecuritySchemeType || scheme.Type == v310.OpenIDConnectSecuritySchemeType:
+		if introspector, ok := w.tokenIntrospectors[name]; ok {
+			info, err := introspector.Introspect(c, str)
+			if err != nil {
+				return nil, securityError(err)
+			} else if info == nil || !info.Active {
+				return nil, ErrSecurityCredentialsInvalid
+			} else if !containsAll(info.Scopes, scopes) {
+				return nil, ErrInsufficientScope
+			}
+			cred.Principal = info.Principal
+		} else if w.Config.JWT != nil {
+			claims, err := w.checkJWT(str, scopes)
+			if err != nil {
+				return nil, err
+			}
+			cred.Claims = claims
+		} else if !hasVerifier {
+			// Fail closed, as nothing is configured to check the token or its scopes
+			return nil, ErrSecurityCredentialsInvalid
+		}
+	case scheme.Type == v310.HTTPSecuritySchemeType &&
+		strings.EqualFold(scheme.Scheme, "bearer") &&
+		strings.EqualFold(scheme.BearerFormat, "JWT"):
+
+		if w.Config.JWT != nil {
+			claims, err := w.checkJWT(str, scopes)
+			if err != nil {
+				return nil, err
+			}
+			cred.Claims = claims
+		} else if !hasVerifier {
+			return nil, ErrSecurityCredentialsInvalid
+		}
+	}
+
+	if verifier, ok := w.securityVerifiers[name]; ok {
+		principal, err := verifier(c, str, scopes)
+		if err != nil {
+			return nil, securityError(err)
+		}
+		if principal != nil {
+			cred.Principal = principal
+		}
+	}
+
+	return cred, nil
+}
+
+// checkJWT validates a bearer token against the configured JWT keys and checks the granted scopes
+func (w *APIWrapper) checkJWT(token string, scopes []string) (*JWTClaims, error) {
+	claims, err := w.Config.JWT.Parse(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSecurityCredentialsInvalid, err)
+	} else if !containsAll(claims.Scopes, scopes) {
+		return nil, ErrInsufficientScope
+	}
+	return claims, nil
+}
+
+// securityError maps errors from verifiers to ErrSecurityCredentialsInvalid unless they already carry a status.
+func securityError(err error) error {
+	var he *echo.HTTPError
+	if errors.Is(err, ErrSecurityAccessDenied) ||
+		errors.Is(err, ErrInsufficientScope) ||
+		errors.Is(err, ErrSecurityCredentialsInvalid) ||
+		errors.As(err, &he) {
+		return err
+	}
+	return fmt.Errorf("%w: %s", ErrSecurityCredentialsInvalid, err)
+}
+
+// containsAll returns true if every required value is present in the granted slice
+func containsAll(granted []string, required []string) bool {
+	for _, r := range required {
+		found := false
+		for _, g := range granted {
+			if g == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// extractSecurityValue reads the credential for a security scheme from the request.
+// Returns false if the credential is not present or cannot be parsed.
+func extractSecurityValue(c echo.Context, scheme *v310.SecurityScheme) (interface{}, bool) {
+	switch scheme.Type {
+	case v310.HTTPSecuritySchemeType:
+		return extractHTTPSecurityValue(c, scheme)
+	case v310.MutualTLSSecuritySchemeType:
+		// Only certificates verified against the configured client CAs are accepted
+		state := c.Request().TLS
+		if state == nil || len(state.VerifiedChains) == 0 || len(state.PeerCertificates) == 0 {
+			return nil, false
+		}
+		return state.PeerCertificates[0].Subject.String(), true
+	case v310.OAuth2SecuritySchemeType, v310.OpenIDConnectSecuritySchemeType:
+		// Access tokens are presented as bearer tokens (RFC 6750)
+		return extractHTTPSecurityValue(c, &v310.SecurityScheme{Scheme: "bearer"})
+	default:
+		switch scheme.In {
+		case "header":
+			val, ok := c.Request().Header[http.CanonicalHeaderKey(scheme.Name)]
+			if ok && len(val) > 0 {
+				return val[0], true
+			}
+			return nil, false
+		case "query":
+			val := c.QueryParam(scheme.Name)
+			if val != "" {
+				return val, true
+			}
+			return nil, false
+		case "cookie":
+			cookie, err := c.Cookie(scheme.Name)
+			if err == nil && cookie.Value != "" {
+				return cookie.Value, true
+			}
+			return nil, false
+		default:
+			// Unknown locations can never carry a credential
+			return nil, false
+		}
+	}
+}
+
+// extractHTTPSecurityValue parses the Authorization header according to the HTTP authentication scheme.
+// Bearer schemes return the token string, basic schemes return BasicCredentials.
+func extractHTTPSecurityValue(c echo.Context, scheme *v310.SecurityScheme) (interface{}, bool) {
+	// Auth scheme names are case-insensitive (RFC 7235)
+	authScheme := strings.ToLower(scheme.Scheme)
+
+	auth := c.Request().Header.Get(echo.HeaderAuthorization)
+	prefix, value, found := strings.Cut(auth, " ")
+	if !found || strings.ToLower(prefix) != authScheme {
+		return nil, false
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, false
+	}
+
+	switch authScheme {
+	case "bearer":
+		return value, true
+	case "basic":
+		buf, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, false
+		}
+		user, pass, found := strings.Cut(string(buf), ":")
+		if !found {
+			return nil, false
+		}
+		return BasicCredentials{Username: user, Password: pass}, true
+	default:
+		// Other schemes (such as digest) need a challenge-response exchange which is not supported
+		return nil, false
+	}
+}
@@ -0,0 +1,491 @@
+package echopen_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/richjyoung/echopen"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityBearer(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+	api.Spec.GetComponents().AddSecurityScheme("bearer", &v310.SecurityScheme{
+		Type:   v310.HTTPSecuritySchemeType,
+		Scheme: "bearer",
+	})
+	api.GET(
+		"/",
+		func(c echo.Context) error {
+			assert.Equal(t, "abcd1234", c.Get("security.bearer"))
+			return c.NoContent(204)
+		},
+		echopen.WithSecurityRequirement("bearer", []string{}),
+	)
+
+	_, res := executeRequest(api, http.MethodGet, "/", nil)
+	assert.Equal(t, 401, res.Result().StatusCode)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Basic abcd1234")
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Result().StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "bearer abcd1234")
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 204, res.Result().StatusCode)
+}
+
+func TestSecurityBasic(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+	api.Spec.GetComponents().AddSecurityScheme("basic", &v310.SecurityScheme{
+		Type:   v310.HTTPSecuritySchemeType,
+		Scheme: "basic",
+	})
+	api.GET(
+		"/",
+		func(c echo.Context) error {
+			creds := c.Get("security.basic").(echopen.BasicCredentials)
+			assert.Equal(t, "user", creds.Username)
+			assert.Equal(t, "pass:word", creds.Password)
+			return c.NoContent(204)
+		},
+		echopen.WithSecurityRequirement("basic", []string{}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Basic not-base64!")
+	res := httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Result().StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("user", "pass:word")
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 204, res.Result().StatusCode)
+}
+
+func TestSecurityUnsupported(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+	api.Spec.GetComponents().AddSecurityScheme("digest", &v310.SecurityScheme{
+		Type:   v310.HTTPSecuritySchemeType,
+		Scheme: "digest",
+	})
+	api.Spec.GetComponents().AddSecurityScheme("api_key", &v310.SecurityScheme{
+		Type: v310.APIKeySecuritySchemeType,
+		In:   "body",
+		Name: "api_key",
+	})
+	api.GET("/digest", func(c echo.Context) error { return c.NoContent(204) }, echopen.WithSecurityRequirement("digest", []string{}))
+	api.GET("/api_key", func(c echo.Context) error { return c.NoContent(204) }, echopen.WithSecurityRequirement("api_key", []string{}))
+
+	// Unsupported schemes and locations are never satisfied
+	req := httptest.NewRequest(http.MethodGet, "/digest", nil)
+	req.Header.Set("Authorization", `Digest username="user"`)
+	res := httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Result().StatusCode)
+
+	_, res = executeRequest(api, http.MethodGet, "/api_key", nil)
+	assert.Equal(t, 401, res.Result().StatusCode)
+}
+
+func TestSecurityAPIKeyQuery(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+	api.Spec.GetComponents().AddSecurityScheme("api_key", &v310.SecurityScheme{
+		Type: v310.APIKeySecuritySchemeType,
+		In:   "query",
+		Name: "api_key",
+	})
+	api.GET(
+		"/",
+		func(c echo.Context) error {
+			assert.Equal(t, "abcd1234", c.Get("security.api_key"))
+			return c.NoContent(204)
+		},
+		echopen.WithSecurityRequirement("api_key", []string{}),
+	)
+
+	_, res := executeRequest(api, http.MethodGet, "/", nil)
+	assert.Equal(t, 401, res.Result().StatusCode)
+
+	_, res = executeRequest(api, http.MethodGet, "/?api_key=abcd1234", nil)
+	assert.Equal(t, 204, res.Result().StatusCode)
+}
+
+func TestSecurityAPIKeyCookie(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+	api.Spec.GetComponents().AddSecurityScheme("session", &v310.SecurityScheme{
+		Type: v310.APIKeySecuritySchemeType,
+		In:   "cookie",
+		Name: "session_id",
+	})
+	api.GET(
+		"/",
+		func(c echo.Context) error {
+			assert.Equal(t, "abcd1234", c.Get("security.session"))
+			return c.NoContent(204)
+		},
+		echopen.WithSecurityRequirement("session", []string{}),
+	)
+
+	_, res := executeRequest(api, http.MethodGet, "/", nil)
+	assert.Equal(t, 401, res.Result().StatusCode)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "session_id", Value: "abcd1234"})
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 204, res.Result().StatusCode)
+}
+
+func TestSecurityVerifier(t *testing.T) {
+	type User struct {
+		Name string
+	}
+
+	api := echopen.New(
+		"Test",
+		"1.0.0",
+		echopen.WithSecurityVerifier("api_key", func(c echo.Context, value string, scopes []string) (interface{}, error) {
+			switch value {
+			case "valid":
+				return &User{Name: "test"}, nil
+			case "readonly":
+				return nil, echopen.ErrSecurityAccessDenied
+			default:
+				return nil, fmt.Errorf("unknown key")
+			}
+		}),
+	)
+	api.Spec.GetComponents().AddSecurityScheme("api_key", &v310.SecurityScheme{
+		Type: v310.APIKeySecuritySchemeType,
+		In:   "header",
+		Name: "X-API-Key",
+	})
+	api.GET(
+		"/",
+		func(c echo.Context) error {
+			user := c.Get("security.api_key.principal").(*User)
+			assert.Equal(t, "test", user.Name)
+			return c.NoContent(204)
+		},
+		echopen.WithSecurityRequirement("api_key", []string{}),
+	)
+
+	for key, code := range map[string]int{"valid": 204, "readonly": 403, "invalid": 401} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", key)
+		res := httptest.NewRecorder()
+		api.Engine.ServeHTTP(res, req)
+		assert.Equal(t, code, res.Result().StatusCode, key)
+	}
+}
+
+func TestSecurityOAuth2Scopes(t *testing.T) {
+	api := echopen.New(
+		"Test",
+		"1.0.0",
+		echopen.WithTokenIntrospector("oauth", echopen.InMemoryIntrospector{
+			"reader":  {Active: true, Scopes: []string{"pets:read"}, Principal: "reader"},
+			"writer":  {Active: true, Scopes: []string{"pets:read", "pets:write"}, Principal: "writer"},
+			"expired": {Active: false, Scopes: []string{"pets:read", "pets:write"}},
+		}),
+	)
+	api.Spec.GetComponents().AddSecurityScheme("oauth", &v310.SecurityScheme{
+		Type: v310.OAuth2SecuritySchemeType,
+		Flows: &v310.OAuthFlows{
+			ClientCredentials: &v310.OAuthFlow{
+				TokenURL: "https://example.com/token",
+				Scopes:   map[string]string{"pets:read": "Read pets", "pets:write": "Write pets"},
+			},
+		},
+	})
+	api.POST(
+		"/",
+		func(c echo.Context) error {
+			assert.Equal(t, "writer", c.Get("security.oauth.principal"))
+			return c.NoContent(204)
+		},
+		echopen.WithSecurityRequirement("oauth", []string{"pets:write"}),
+	)
+
+	for token, code := range map[string]int{"writer": 204, "reader": 403, "expired": 401, "unknown": 401} {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		res := httptest.NewRecorder()
+		api.Engine.ServeHTTP(res, req)
+		assert.Equal(t, code, res.Result().StatusCode, token)
+	}
+}
+
+func TestSecurityTokenUnverified(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+	api.Spec.GetComponents().AddSecurityScheme("oauth", &v310.SecurityScheme{
+		Type: v310.OAuth2SecuritySchemeType,
+		Flows: &v310.OAuthFlows{
+			ClientCredentials: &v310.OAuthFlow{
+				TokenURL: "https://example.com/token",
+				Scopes:   map[string]string{"pets:write": "Write pets"},
+			},
+		},
+	})
+	api.Spec.GetComponents().AddSecurityScheme("jwt", &v310.SecurityScheme{
+		Type:         v310.HTTPSecuritySchemeType,
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+	})
+	api.GET("/oauth", func(c echo.Context) error { return c.NoContent(204) }, echopen.WithSecurityRequirement("oauth", []string{"pets:write"}))
+	api.GET("/jwt", func(c echo.Context) error { return c.NoContent(204) }, echopen.WithSecurityRequirement("jwt", []string{}))
+
+	// Tokens are rejected when nothing is configured to verify them
+	for _, path := range []string{"/oauth", "/jwt"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer anything")
+		res := httptest.NewRecorder()
+		api.Engine.ServeHTTP(res, req)
+		assert.Equal(t, 401, res.Result().StatusCode, path)
+	}
+}
+
+func TestSecurityRequirementAndOr(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+	api.Spec.GetComponents().AddSecurityScheme("api_key", &v310.SecurityScheme{
+		Type: v310.APIKeySecuritySchemeType,
+		In:   "header",
+		Name: "X-API-Key",
+	})
+	api.Spec.GetComponents().AddSecurityScheme("client_id", &v310.SecurityScheme{
+		Type: v310.APIKeySecuritySchemeType,
+		In:   "header",
+		Name: "X-Client-ID",
+	})
+	api.Spec.GetComponents().AddSecurityScheme("bearer", &v310.SecurityScheme{
+		Type:   v310.HTTPSecuritySchemeType,
+		Scheme: "bearer",
+	})
+
+	// Either (api_key AND client_id) OR bearer
+	combined := &v310.SecurityRequirement{"api_key": {}, "client_id": {}}
+	api.GET(
+		"/",
+		func(c echo.Context) error {
+			req := c.Get("security").(*v310.SecurityRequirement)
+			_, ok := (*req)["bearer"]
+			return c.JSON(200, map[string]interface{}{"bearer": ok})
+		},
+		echopen.WithCombinedSecurityRequirement(combined),
+		echopen.WithSecurityRequirement("bearer", []string{}),
+	)
+
+	type tcd struct {
+		Name    string
+		Headers map[string]string
+		Code    int
+		Body    string
+	}
+
+	defs := []tcd{
+		{"none", map[string]string{}, 401, ""},
+		{"api_key_only", map[string]string{"X-API-Key": "key"}, 401, ""},
+		{"client_id_only", map[string]string{"X-Client-ID": "id"}, 401, ""},
+		{"combined", map[string]string{"X-API-Key": "key", "X-Client-ID": "id"}, 200, `{"bearer":false}`},
+		{"bearer", map[string]string{"Authorization": "Bearer token"}, 200, `{"bearer":true}`},
+	}
+
+	for _, tc := range defs {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tc.Headers {
+				req.Header.Set(k, v)
+			}
+			res := httptest.NewRecorder()
+			api.Engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.Code, res.Result().StatusCode)
+			if tc.Body != "" {
+				assert.JSONEq(t, tc.Body, res.Body.String())
+			}
+		})
+	}
+}
+
+func TestGroupSecurityRequirementCombined(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+	api.Spec.GetComponents().AddSecurityScheme("api_key", &v310.SecurityScheme{
+		Type: v310.APIKeySecuritySchemeType,
+		In:   "header",
+		Name: "X-API-Key",
+	})
+	api.Spec.GetComponents().AddSecurityScheme("client_id", &v310.SecurityScheme{
+		Type: v310.APIKeySecuritySchemeType,
+		In:   "header",
+		Name: "X-Client-ID",
+	})
+
+	g := api.Group("/group", echopen.WithGroupSecurityRequirement(&v310.SecurityRequirement{"api_key": {}, "client_id": {}}))
+	g.GET("", func(c echo.Context) error { return c.NoContent(204) })
+
+	assert.Len(t, api.Spec.Paths["/group"].Value.Get.Security, 1)
+
+	req := httptest.NewRequest(http.MethodGet, "/group", nil)
+	req.Header.Set("X-API-Key", "key")
+	res := httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Result().StatusCode)
+
+	req.Header.Set("X-Client-ID", "id")
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 204, res.Result().StatusCode)
+}
+
+func TestSecuritySpecRequirement(t *testing.T) {
+	api := echopen.New(
+		"Test",
+		"1.0.0",
+		echopen.WithSpecSecurityScheme("api_key", &v310.SecurityScheme{
+			Type: v310.APIKeySecuritySchemeType,
+			In:   "header",
+			Name: "X-API-Key",
+		}),
+		echopen.WithSpecSecurityRequirement("api_key", []string{}),
+	)
+
+	api.GET("/secure", func(c echo.Context) error { return c.NoContent(204) })
+	api.GET("/open", func(c echo.Context) error { return c.NoContent(204) }, echopen.WithOptionalSecurity())
+
+	_, res := executeRequest(api, http.MethodGet, "/secure", nil)
+	assert.Equal(t, 401, res.Result().StatusCode)
+
+	_, res = executeRequest(api, http.MethodGet, "/open", nil)
+	assert.Equal(t, 204, res.Result().StatusCode)
+
+	req := httptest.NewRequest(http.MethodGet, "/secure", nil)
+	req.Header.Set("X-API-Key", "key")
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 204, res.Result().StatusCode)
+}
+
+func TestSecurityOptionalFirst(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+	api.Spec.GetComponents().AddSecurityScheme("api_key", &v310.SecurityScheme{
+		Type: v310.APIKeySecuritySchemeType,
+		In:   "header",
+		Name: "X-API-Key",
+	})
+	api.GET(
+		"/",
+		func(c echo.Context) error {
+			return c.JSON(200, map[string]interface{}{"key": c.Get("security.api_key")})
+		},
+		echopen.WithOptionalSecurity(),
+		echopen.WithSecurityRequirement("api_key", []string{}),
+	)
+
+	// Credentials are still picked up when sent, even though the empty requirement is listed first
+	_, res := executeRequest(api, http.MethodGet, "/", nil)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.JSONEq(t, `{"key":null}`, res.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-API-Key", "abcd1234")
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.JSONEq(t, `{"key":"abcd1234"}`, res.Body.String())
+}
+
+func generateCert(t *testing.T, cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	if parent == nil {
+		// Self-signed CA
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	return cert, key
+}
+
+func TestSecurityMutualTLS(t *testing.T) {
+	api := echopen.New(
+		"Test",
+		"1.0.0",
+		echopen.WithSpecSecurityScheme("client_cert", &v310.SecurityScheme{
+			Type: v310.MutualTLSSecuritySchemeType,
+		}),
+	)
+	api.GET(
+		"/",
+		func(c echo.Context) error {
+			return c.String(200, c.Get("security.client_cert").(string))
+		},
+		echopen.WithSecurityRequirement("client_cert", []string{}),
+	)
+
+	ca, caKey := generateCert(t, "Test CA", nil, nil)
+	client, clientKey := generateCert(t, "client.example.com", ca, caKey)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	srv := httptest.NewUnstartedServer(api.Engine)
+	srv.TLS = echopen.NewMutualTLSConfig(pool)
+	srv.StartTLS()
+	defer srv.Close()
+
+	// No client certificate
+	res, err := srv.Client().Get(srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 401, res.StatusCode)
+
+	// Verified client certificate
+	httpClient := srv.Client()
+	httpClient.Transport.(*http.Transport).TLSClientConfig.Certificates = []tls.Certificate{{
+		Certificate: [][]byte{client.Raw},
+		PrivateKey:  clientKey,
+	}}
+	res, err = httpClient.Get(srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(t, "CN=client.example.com", string(body))
+
+	// Plain HTTP
+	_, rec := executeRequest(api, http.MethodGet, "/", nil)
+	assert.Equal(t, 401, rec.Result().StatusCode)
+}
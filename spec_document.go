@@ -0,0 +1,115 @@
+package echopen
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const headerETag = "ETag"
+
+// specDocument serves a marshalled copy of the spec, which is built on the first request and cached.
+// The document is rebuilt when the spec generation changes, which happens as the wrapper changes the spec, until the spec is frozen.
+type specDocument struct {
+	api         *APIWrapper
+	filters     []SpecFilterFunc
+	contentType string
+	marshal     func(interface{}) ([]byte, error)
+
+	mu           sync.Mutex
+	built        bool
+	frozen       bool
+	generation   uint64
+	body         []byte
+	etag         string
+	lastModified time.Time
+}
+
+// Freeze marks the spec as final, so served documents are rebuilt one last time and then served from the cache.
+// Adding routes after the spec is frozen panics.
+func (w *APIWrapper) Freeze() {
+	w.SpecChanged()
+	w.frozen.Store(true)
+}
+
+// SpecChanged invalidates served documents, so they are rebuilt on the next request.
+// Adding routes and groups, and the wrapper Set and With functions, do this automatically.
+// Direct changes to the Spec are not detected, so this must be called after making them.
+func (w *APIWrapper) SpecChanged() {
+	w.specGeneration.Add(1)
+}
+
+// Frozen reports whether Freeze has been called
+func (w *APIWrapper) Frozen() bool {
+	return w.frozen.Load()
+}
+
+func (d *specDocument) handler(c echo.Context) error {
+	body, etag, lastModified, err := d.get()
+	if err != nil {
+		return err
+	}
+
+	h := c.Response().Header()
+	h.Set(headerETag, etag)
+	h.Set(echo.HeaderLastModified, lastModified.Format(http.TimeFormat))
+
+	if etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, d.contentType, body)
+}
+
+// get returns the current document, rebuilding it if the spec has changed since it was last built
+func (d *specDocument) get() ([]byte, string, time.Time, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	generation := d.api.specGeneration.Load()
+	if d.built && (d.frozen || generation == d.generation) {
+		return d.body, d.etag, d.lastModified, nil
+	}
+	frozen := d.api.Frozen()
+
+	s := d.api.Spec
+	if len(d.filters) > 0 {
+		s = d.api.Spec.Copy()
+		for _, f := range d.filters {
+			s = f(s)
+		}
+	}
+
+	body, err := d.marshal(s)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(body))
+	if etag != d.etag {
+		d.lastModified = time.Now().UTC().Truncate(time.Second)
+	}
+
+	d.built = true
+	d.frozen = frozen
+	d.generation = generation
+	d.body = body
+	d.etag = etag
+
+	return d.body, d.etag, d.lastModified, nil
+}
+
+// etagMatches checks an If-None-Match header against an ETag, using the weak comparison required for GET requests
+func etagMatches(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package echopen_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/richjyoung/echopen"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeSpecLive(t *testing.T) {
+	api := echopen.New(
+		"Test",
+		"1.0.0",
+		echopen.WithSpecTag(&v310.Tag{Name: "pets"}),
+		echopen.WithSpecTag(&v310.Tag{Name: "hidden"}),
+	)
+	api.ServeYAMLSpec("/openapi.yml")
+	api.ServeJSONSpec("/openapi.json", echopen.ExcludeTags("hidden"))
+
+	handler := func(c echo.Context) error { return c.NoContent(204) }
+
+	// Routes added after the spec is served still appear
+	api.GET("/pets", handler, echopen.WithTags("pets"))
+	api.GET("/secret", handler, echopen.WithTags("hidden"))
+
+	_, res := executeRequest(api, http.MethodGet, "/openapi.yml", nil)
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, "application/yaml", res.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, res.Body.String(), "/pets:")
+	assert.Contains(t, res.Body.String(), "/secret:")
+
+	etag := res.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.NotEmpty(t, res.Header().Get(echo.HeaderLastModified))
+
+	_, res = executeRequest(api, http.MethodGet, "/openapi.json", nil)
+	assert.Equal(t, 200, res.Code)
+	assert.Contains(t, res.Body.String(), `"/pets"`)
+	assert.NotContains(t, res.Body.String(), `"/secret"`)
+
+	// Unchanged documents are not resent
+	for _, inm := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		req := httptest.NewRequest(http.MethodGet, "/openapi.yml", nil)
+		req.Header.Set("If-None-Match", inm)
+		res = httptest.NewRecorder()
+		api.Engine.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusNotModified, res.Code, inm)
+		assert.Equal(t, etag, res.Header().Get("ETag"))
+		assert.Empty(t, res.Body.String())
+	}
+
+	// Changes made through the wrapper are picked up on the next request
+	api.SetSpecDescription("Changed")
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi.yml", nil)
+	req.Header.Set("If-None-Match", etag)
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Code)
+	assert.Contains(t, res.Body.String(), "description: Changed")
+	assert.NotEqual(t, etag, res.Header().Get("ETag"))
+	etag = res.Header().Get("ETag")
+
+	for name, change := range map[string]func(){
+		"terms":         func() { api.SetTermsOfService("https://example.com/tos") },
+		"license":       func() { api.SetSpecLicense(&v310.License{Name: "MIT"}) },
+		"contact":       func() { api.SetSpecContact(&v310.Contact{Name: "Support"}) },
+		"external_docs": func() { api.SetSpecExternalDocs(&v310.ExternalDocs{URL: "https://example.com/docs"}) },
+		"problem":       func() { api.RegisterProblemDetails() },
+	} {
+		change()
+		_, res = executeRequest(api, http.MethodGet, "/openapi.yml", nil)
+		assert.NotEqual(t, etag, res.Header().Get("ETag"), name)
+		etag = res.Header().Get("ETag")
+	}
+	assert.Contains(t, res.Body.String(), "termsOfService: https://example.com/tos")
+	assert.Contains(t, res.Body.String(), "ProblemDetails:")
+
+	// Direct changes to the spec are picked up once SpecChanged is called
+	api.Spec.Info.Description = "Direct"
+	api.SpecChanged()
+
+	_, res = executeRequest(api, http.MethodGet, "/openapi.yml", nil)
+	assert.Contains(t, res.Body.String(), "description: Direct")
+	assert.NotEqual(t, etag, res.Header().Get("ETag"))
+	etag = res.Header().Get("ETag")
+
+	// As does adding a route
+	api.GET("/late", handler)
+	_, res = executeRequest(api, http.MethodGet, "/openapi.yml", nil)
+	assert.Contains(t, res.Body.String(), "/late:")
+	assert.NotEqual(t, etag, res.Header().Get("ETag"))
+}
+
+func TestServeSpecFreeze(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+	api.ServeYAMLSpec("/openapi.yml")
+
+	_, res := executeRequest(api, http.MethodGet, "/openapi.yml", nil)
+	etag := res.Header().Get("ETag")
+
+	// Changes made before freezing are picked up once more
+	api.Spec.Info.Description = "Final"
+	api.Freeze()
+	assert.True(t, api.Frozen())
+
+	_, res = executeRequest(api, http.MethodGet, "/openapi.yml", nil)
+	assert.Contains(t, res.Body.String(), "description: Final")
+	frozen := res.Header().Get("ETag")
+	assert.NotEqual(t, etag, frozen)
+
+	// After which the document is no longer rebuilt
+	api.Spec.Info.Description = "Ignored"
+	api.SpecChanged()
+	_, res = executeRequest(api, http.MethodGet, "/openapi.yml", nil)
+	assert.Contains(t, res.Body.String(), "description: Final")
+	assert.Equal(t, frozen, res.Header().Get("ETag"))
+
+	assert.PanicsWithValue(t, "echopen: cannot add GET /late after the spec is frozen", func() {
+		api.GET("/late", func(c echo.Context) error { return nil })
+	})
+	assert.Panics(t, func() {
+		api.Group("/v1").GET("/late", func(c echo.Context) error { return nil })
+	})
+}
+
+func TestServeSpecFilteredStructs(t *testing.T) {
+	type Pet struct {
+		Name string   `json:"name" default:"Rex" example:"Fido"`
+		Tags []string `json:"tags"`
+	}
+
+	api := echopen.New(
+		"Test",
+		"1.0.0",
+		echopen.WithSpecTag(&v310.Tag{Name: "pets"}),
+		echopen.WithSpecTag(&v310.Tag{Name: "hidden"}),
+	)
+	api.ServeYAMLSpec("/openapi.yml", echopen.ExcludeTags("hidden"))
+
+	handler := func(c echo.Context) error { return c.NoContent(204) }
+	api.POST("/pets", handler, echopen.WithTags("pets"), echopen.WithRequestBodyStruct(echo.MIMEApplicationJSON, "Pet", Pet{}))
+	api.GET("/secret", handler, echopen.WithTags("hidden"), echopen.WithResponseStruct("200", "Pet", Pet{}))
+
+	_, res := executeRequest(api, http.MethodGet, "/openapi.yml", nil)
+	assert.Equal(t, 200, res.Code)
+	assert.Contains(t, res.Body.String(), "/pets:")
+	assert.Contains(t, res.Body.String(), "default: Rex")
+	assert.NotContains(t, res.Body.String(), "/secret:")
+
+	// Filtering works on a copy, leaving the original spec intact
+	assert.NotNil(t, api.Spec.Paths["/secret"])
+	assert.Equal(t, []string{"hidden"}, api.Spec.Paths["/secret"].Value.Get.Tags)
+}
@@ -0,0 +1,162 @@
+package echopen
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+)
+
+// NewFromSpec creates a wrapper from an existing OpenAPI 3.1 document, and registers a route for every operation in it.
+// Implementations are attached by operation ID with Handle, and operations without one respond with 501 Not Implemented.
+// Parameters, request bodies, and security requirements are enforced from the loaded definitions.
+func NewFromSpec(file string, config ...WrapperConfigFunc) (*APIWrapper, error) {
+	spec, err := v310.LoadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refs are resolved on each request, so any that cannot be are reported up front
+	if err := spec.CheckRefs(); err != nil {
+		return nil, err
+	}
+
+	// Replace the default spec before any other config is applied, so it adds to the loaded one
+	config = append([]WrapperConfigFunc{func(w *APIWrapper) *APIWrapper {
+		w.Spec = spec
+		return w
+	}}, config...)
+
+	wrapper := New(spec.Info.Title, spec.Info.Version, config...)
+	if err := wrapper.addSpecRoutes(); err != nil {
+		return nil, err
+	}
+	return wrapper, nil
+}
+
+// Handle attaches the implementation of an operation loaded by NewFromSpec.
+// Handlers should be attached before the server starts.
+func (w *APIWrapper) Handle(operationID string, handler echo.HandlerFunc) *RouteWrapper {
+	r, ok := w.operations[operationID]
+	if !ok {
+		panic(fmt.Sprintf("echopen: no operation %s in the spec", operationID))
+	}
+	r.Handler = handler
+	return r
+}
+
+// MissingHandlers returns the IDs of operations loaded by NewFromSpec which have no handler attached
+func (w *APIWrapper) MissingHandlers() []string {
+	missing := []string{}
+	for id, r := range w.operations {
+		if r.Handler == nil {
+			missing = append(missing, id)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// CheckHandlers returns an error listing any operations without a handler, for use before starting the server
+func (w *APIWrapper) CheckHandlers() error {
+	if missing := w.MissingHandlers(); len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrHandlersMissing, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// addSpecRoutes registers a route for each operation in the spec, in path order
+func (w *APIWrapper) addSpecRoutes() error {
+	w.operations = map[string]*RouteWrapper{}
+
+	paths := make([]string, 0, len(w.Spec.Paths))
+	for path := range w.Spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pathItem, _ := w.Spec.Paths[path].DeRef(w.Spec.Components).(*v310.PathItem)
+		if pathItem == nil {
+			return fmt.Errorf("echopen: cannot resolve path item for %s", path)
+		}
+
+		ops := []struct {
+			method string
+			op     *v310.Operation
+		}{
+			{http.MethodGet, pathItem.Get},
+			{http.MethodPut, pathItem.Put},
+			{http.MethodPost, pathItem.Post},
+			{http.MethodDelete, pathItem.Delete},
+			{http.MethodOptions, pathItem.Options},
+			{http.MethodHead, pathItem.Head},
+			{http.MethodPatch, pathItem.Patch},
+			{http.MethodTrace, pathItem.Trace},
+		}
+
+		for _, o := range ops {
+			if o.op == nil {
+				continue
+			} else if o.op.OperationID == "" {
+				return fmt.Errorf("echopen: %s %s has no operationId", o.method, path)
+			} else if _, ok := w.operations[o.op.OperationID]; ok {
+				return fmt.Errorf("echopen: duplicate operationId %s", o.op.OperationID)
+			}
+			w.operations[o.op.OperationID] = w.addSpecRoute(o.method, openAPIRouteToEcho(path), pathItem, o.op)
+		}
+	}
+	return nil
+}
+
+// addSpecRoute registers a route for an operation already in the spec, leaving the operation unchanged
+func (w *APIWrapper) addSpecRoute(method string, path string, pathItem *v310.PathItem, op *v310.Operation) *RouteWrapper {
+	wrapper := &RouteWrapper{
+		API:               w,
+		Method:            method,
+		Path:              path,
+		Operation:         op,
+		PathItem:          pathItem,
+		RequestBodySchema: map[string]*v310.Schema{},
+	}
+
+	// Request bodies have no Go types, so JSON bodies are validated against their schemas
+	if op.RequestBody != nil {
+		if rb, ok := op.RequestBody.DeRef(w.Spec.Components).(*v310.RequestBody); ok && rb != nil {
+			for mime, mt := range rb.Content {
+				var schema *v310.Schema
+				if mt != nil && mt.Schema != nil {
+					schema, _ = mt.Schema.DeRef(w.Spec.Components).(*v310.Schema)
+				}
+				if schema == nil {
+					schema = &v310.Schema{}
+				}
+				wrapper.RequestBodySchema[mime] = schema
+			}
+		}
+	}
+
+	middlewares := []echo.MiddlewareFunc{}
+	if !w.Config.DisableDefaultMiddleware {
+		middlewares = append(middlewares, wrapper.middleware())
+	}
+	if w.Config.ValidateResponses != ResponseValidationDisabled {
+		middlewares = append(middlewares, wrapper.responseValidationMiddleware())
+	}
+
+	// The handler is looked up on each request, as it is attached after the route is registered
+	handler := func(c echo.Context) error {
+		if wrapper.Handler == nil {
+			return ErrNotImplemented
+		}
+		return wrapper.Handler(c)
+	}
+
+	wrapper.Route = w.Engine.Add(method, w.Config.BaseURL+path, handler, middlewares...)
+	wrapper.Route.Name = op.OperationID
+
+	return wrapper
+}
@@ -0,0 +1,264 @@
+package echopen_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/richjyoung/echopen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFromSpec(t *testing.T) {
+	api, err := echopen.NewFromSpec("examples/petstore/petstore.yml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Swagger Petstore", api.Spec.Info.Title)
+
+	// Nothing is implemented yet
+	assert.Equal(t, []string{"addPet", "deletePet", "find pet by id", "findPets"}, api.MissingHandlers())
+	err = api.CheckHandlers()
+	assert.True(t, errors.Is(err, echopen.ErrHandlersMissing))
+	assert.Contains(t, err.Error(), "addPet, deletePet, find pet by id, findPets")
+
+	_, res := executeRequest(api, http.MethodGet, "/pets", nil)
+	assert.Equal(t, http.StatusNotImplemented, res.Code)
+
+	// Parameters are still enforced for unimplemented operations
+	_, res = executeRequest(api, http.MethodDelete, "/pets/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+	api.Handle("findPets", func(c echo.Context) error {
+		limit, _ := echopen.QueryParam[int](c, "limit")
+		tags, _ := echopen.QueryParam[[]string](c, "tags")
+		return c.JSON(http.StatusOK, map[string]interface{}{"limit": limit, "tags": tags})
+	})
+	api.Handle("addPet", func(c echo.Context) error {
+		body, err := echopen.Body[map[string]interface{}](c)
+		if err != nil {
+			return err
+		}
+		body["id"] = 1
+		return c.JSON(http.StatusOK, body)
+	})
+	api.Handle("find pet by id", func(c echo.Context) error {
+		id, err := echopen.PathParam[int64](c, "id")
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{"id": id, "name": "Rex"})
+	})
+
+	assert.Equal(t, []string{"deletePet"}, api.MissingHandlers())
+
+	_, res = executeRequest(api, http.MethodGet, "/pets?limit=5&tags=a&tags=b", nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"limit":5,"tags":["a","b"]}`, res.Body.String())
+
+	_, res = executeRequest(api, http.MethodGet, "/pets?limit=many", nil)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+	_, res = executeRequest(api, http.MethodGet, "/pets/12", nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"id":12,"name":"Rex"}`, res.Body.String())
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		code        int
+	}{
+		{"valid", echo.MIMEApplicationJSON, `{"name":"Rex","tag":"dog"}`, http.StatusOK},
+		{"missing_name", echo.MIMEApplicationJSON, `{"tag":"dog"}`, http.StatusBadRequest},
+		{"wrong_type", echo.MIMEApplicationJSON, `{"name":1}`, http.StatusBadRequest},
+		{"no_body", "", "", http.StatusUnsupportedMediaType},
+		{"wrong_content_type", echo.MIMETextPlain, "Rex", http.StatusUnsupportedMediaType},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set(echo.HeaderContentType, tc.contentType)
+			}
+			res := httptest.NewRecorder()
+			api.Engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.code, res.Code, res.Body.String())
+		})
+	}
+
+	assert.PanicsWithValue(t, "echopen: no operation updatePet in the spec", func() {
+		api.Handle("updatePet", func(c echo.Context) error { return nil })
+	})
+
+	// The loaded operations are left as they were
+	assert.Len(t, api.Spec.Paths, 2)
+	assert.Equal(t, "findPets", api.Spec.Paths["/pets"].Value.Get.OperationID)
+	assert.Nil(t, api.Spec.Paths["/pets"].Value.Get.Responses["400"])
+}
+
+func TestNewFromSpecErrors(t *testing.T) {
+	_, err := echopen.NewFromSpec("examples/missing.yml")
+	assert.Error(t, err)
+
+	tests := []struct {
+		name  string
+		paths string
+		err   string
+	}{
+		{"no_operation_id", `{"/pets": {"get": {}}}`, "echopen: GET /pets has no operationId"},
+		{"duplicate_operation_id", `{"/a": {"get": {"operationId": "get"}}, "/b": {"get": {"operationId": "get"}}}`, "echopen: duplicate operationId get"},
+		{"unresolved_path_item", `{"/pets": {"$ref": "#/components/pathItems/Pets"}}`, "v310: $ref #/components/pathItems/Pets does not resolve to a PathItem component"},
+		{"unsupported_ref", `{"/pets": {"post": {"operationId": "addPet", "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/$defs/Node"}}}}}}}`, "v310: unsupported $ref #/$defs/Node, only #/components/<type>/<name> refs are supported"},
+		{"external_ref", `{"/pets": {"get": {"operationId": "getPets", "parameters": [{"$ref": "common.yml#/components/parameters/Limit"}]}}}`, "v310: unsupported $ref common.yml#/components/parameters/Limit, only #/components/<type>/<name> refs are supported"},
+		{"deep_ref", `{"/pets": {"get": {"operationId": "getPets", "parameters": [{"$ref": "#/components/schemas/Pet/properties/limit"}]}}}`, "v310: unsupported $ref #/components/schemas/Pet/properties/limit, only #/components/<type>/<name> refs are supported"},
+		{"wrong_component_type", `{"/pets": {"get": {"operationId": "getPets", "parameters": [{"$ref": "#/components/schemas/Limit"}]}}}`, "v310: $ref #/components/schemas/Limit does not resolve to a Parameter component"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "openapi.json")
+			doc := `{"openapi": "3.1.0", "info": {"title": "Test", "version": "1.0.0"}, "paths": ` + tc.paths + `, "components": {}}`
+			assert.NoError(t, os.WriteFile(file, []byte(doc), 0o600))
+
+			_, err := echopen.NewFromSpec(file)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestNewFromSpecPathItemParameters(t *testing.T) {
+	doc := `{
+		"openapi": "3.1.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {
+			"/items/{id}": {
+				"parameters": [
+					{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}},
+					{"name": "X-Tenant", "in": "header", "required": true, "schema": {"type": "string"}}
+				],
+				"get": {
+					"operationId": "getItem",
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}]
+				}
+			}
+		}
+	}`
+	file := filepath.Join(t.TempDir(), "openapi.json")
+	assert.NoError(t, os.WriteFile(file, []byte(doc), 0o600))
+
+	api, err := echopen.NewFromSpec(file)
+	if !assert.NoError(t, err) {
+		return
+	}
+	api.Handle("getItem", func(c echo.Context) error {
+		id, _ := echopen.PathParam[int](c, "id")
+		tenant, _ := echopen.Header[string](c, "X-Tenant")
+		return c.JSON(http.StatusOK, map[string]interface{}{"id": id, "tenant": tenant})
+	})
+
+	// Parameters on the path item apply to its operations
+	_, res := executeRequest(api, http.MethodGet, "/items/1", nil)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set("X-Tenant", "acme")
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"id":1,"tenant":"acme"}`, res.Body.String())
+
+	// Unless the operation overrides them
+	req = httptest.NewRequest(http.MethodGet, "/items/abc", nil)
+	req.Header.Set("X-Tenant", "acme")
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
+
+func TestNewFromSpecSecurityOptOut(t *testing.T) {
+	doc := `
+openapi: 3.1.0
+info:
+  title: Test
+  version: 1.0.0
+security:
+  - api_key: []
+paths:
+  /secure:
+    get:
+      operationId: secure
+  /open:
+    get:
+      operationId: open
+      security: []
+components:
+  securitySchemes:
+    api_key:
+      type: apiKey
+      in: header
+      name: X-API-Key
+`
+	file := filepath.Join(t.TempDir(), "openapi.yml")
+	assert.NoError(t, os.WriteFile(file, []byte(doc), 0o600))
+
+	api, err := echopen.NewFromSpec(file)
+	if !assert.NoError(t, err) {
+		return
+	}
+	api.Handle("secure", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+	api.Handle("open", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+
+	// The top level requirement applies unless the operation explicitly opts out
+	_, res := executeRequest(api, http.MethodGet, "/secure", nil)
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+	_, res = executeRequest(api, http.MethodGet, "/open", nil)
+	assert.Equal(t, http.StatusNoContent, res.Code)
+}
+
+func TestNewFromSpecArrayWithoutItems(t *testing.T) {
+	doc := `
+openapi: 3.1.0
+info:
+  title: Test
+  version: 1.0.0
+paths:
+  /items:
+    get:
+      operationId: listItems
+      parameters:
+        - name: tags
+          in: query
+          schema:
+            type: array
+        - name: X-Flags
+          in: header
+          schema:
+            type: array
+`
+	file := filepath.Join(t.TempDir(), "openapi.yml")
+	assert.NoError(t, os.WriteFile(file, []byte(doc), 0o600))
+
+	api, err := echopen.NewFromSpec(file)
+	if !assert.NoError(t, err) {
+		return
+	}
+	api.Handle("listItems", func(c echo.Context) error {
+		tags, _ := echopen.QueryParam[[]interface{}](c, "tags")
+		flags, _ := echopen.Header[[]interface{}](c, "X-Flags")
+		return c.JSON(http.StatusOK, map[string]interface{}{"tags": tags, "flags": flags})
+	})
+
+	// Elements are kept as strings when the array declares no items
+	req := httptest.NewRequest(http.MethodGet, "/items?tags=a&tags=1", nil)
+	req.Header.Add("X-Flags", "x")
+	res := httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"tags":["a","1"],"flags":["x"]}`, res.Body.String())
+}
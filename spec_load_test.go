@@ -0,0 +1,429 @@
+package echopen_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLoadSpecExamples(t *testing.T) {
+	files, err := filepath.Glob("examples/*/openapi_out.yml")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(filepath.Dir(file)), func(t *testing.T) {
+			spec, err := v310.LoadFile(file)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			// Written specs are reproduced exactly when loaded and written again
+			orig, err := os.ReadFile(file)
+			assert.NoError(t, err)
+			out, err := yaml.Marshal(spec)
+			assert.NoError(t, err)
+			assert.Equal(t, strings.TrimPrefix(string(orig), "# Specification generated by echOpen\n\n"), string(out))
+
+			// As are JSON documents
+			buf, err := json.Marshal(spec)
+			assert.NoError(t, err)
+			fromJSON, err := v310.ParseJSON(buf)
+			assert.NoError(t, err)
+			again, err := json.Marshal(fromJSON)
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(buf), string(again))
+		})
+	}
+}
+
+func TestLoadSpecSchemas(t *testing.T) {
+	doc := `{
+		"openapi": "3.1.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {
+			"/pets": {
+				"post": {
+					"requestBody": {"$ref": "#/components/requestBodies/Pet"},
+					"responses": {"201": {"description": "Created"}}
+				}
+			}
+		},
+		"components": {
+			"requestBodies": {
+				"Pet": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+			},
+			"schemas": {
+				"Pet": {
+					"type": "object",
+					"required": ["name"],
+					"properties": {
+						"name": {"type": "string"},
+						"tag": {"type": ["string", "null"]},
+						"extra": true
+					},
+					"additionalProperties": false
+				}
+			}
+		}
+	}`
+
+	check := func(t *testing.T, spec *v310.Specification) {
+		assert.Equal(t, "#/components/requestBodies/Pet", spec.Paths["/pets"].Value.Post.RequestBody.Ref)
+		assert.Nil(t, spec.Paths["/pets"].Value.Post.RequestBody.Value)
+
+		body := spec.Components.RequestBodies["Pet"]
+		assert.Equal(t, "#/components/schemas/Pet", body.Content["application/json"].Schema.Ref)
+
+		pet := spec.Components.Schemas["Pet"]
+		assert.Equal(t, v310.ObjectSchemaType, pet.Type)
+		assert.Equal(t, v310.StringSchemaType, pet.Properties["name"].Value.Type)
+		assert.Equal(t, []v310.SchemaType{v310.StringSchemaType, v310.NullSchemaType}, pet.Properties["tag"].Value.Types)
+		assert.Equal(t, true, *pet.Properties["extra"].Value.Boolean)
+		assert.Equal(t, false, *pet.AdditionalProperties.Value.Boolean)
+
+		tests := []struct {
+			name    string
+			val     string
+			keyword string
+			param   string
+		}{
+			{"valid", `{"name":"Rex","tag":"dog","extra":[1]}`, "", ""},
+			{"null_tag", `{"name":"Rex","tag":null}`, "", ""},
+			{"tag_type", `{"name":"Rex","tag":1}`, "type", "string,null"},
+			{"additional", `{"name":"Rex","colour":"brown"}`, "additionalProperties", "colour"},
+		}
+		for _, tc := range tests {
+			var val interface{}
+			assert.NoError(t, json.Unmarshal([]byte(tc.val), &val))
+
+			err := pet.ValidateJSON(val, spec.Components)
+			if tc.keyword == "" {
+				assert.NoError(t, err, tc.name)
+			} else if se, ok := err.(*v310.SchemaError); assert.True(t, ok, tc.name) {
+				assert.Equal(t, tc.keyword, se.Keyword, tc.name)
+				assert.Equal(t, tc.param, se.Param, tc.name)
+			}
+		}
+	}
+
+	spec, err := v310.ParseJSON([]byte(doc))
+	if !assert.NoError(t, err) {
+		return
+	}
+	check(t, spec)
+
+	// Marshalling keeps refs, boolean schemas, and type arrays
+	buf, err := json.Marshal(spec)
+	assert.NoError(t, err)
+	assert.Contains(t, string(buf), `"additionalProperties":false`)
+	assert.Contains(t, string(buf), `"type":["string","null"]`)
+	assert.Contains(t, string(buf), `"extra":true`)
+
+	out, err := yaml.Marshal(spec)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "additionalProperties: false")
+	assert.Contains(t, string(out), "$ref: '#/components/schemas/Pet'")
+
+	fromYAML, err := v310.ParseYAML(out)
+	if assert.NoError(t, err) {
+		check(t, fromYAML)
+	}
+	assert.Equal(t, spec, fromYAML)
+}
+
+func TestLoadSpecPathItemParameters(t *testing.T) {
+	doc := `
+openapi: 3.1.0
+info:
+  title: Test
+  version: 1.0.0
+paths:
+  /items/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: string
+      - $ref: '#/components/parameters/Tenant'
+    get:
+      responses:
+        "200":
+          description: OK
+components:
+  parameters:
+    Tenant:
+      name: X-Tenant
+      in: header
+      schema:
+        type: string
+`
+
+	spec, err := v310.ParseYAML([]byte(doc))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	params := spec.Paths["/items/{id}"].Value.Parameters
+	if assert.Len(t, params, 2) {
+		assert.Equal(t, "id", params[0].Value.Name)
+		assert.Equal(t, "#/components/parameters/Tenant", params[1].Ref)
+	}
+
+	buf, err := json.Marshal(spec)
+	assert.NoError(t, err)
+	fromJSON, err := v310.ParseJSON(buf)
+	if assert.NoError(t, err) {
+		assert.Len(t, fromJSON.Paths["/items/{id}"].Value.Parameters, 2)
+	}
+}
+
+func TestLoadSpecKeywords(t *testing.T) {
+	doc := `
+openapi: 3.1.0
+info:
+  title: Test
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        secret:
+          type: string
+          writeOnly: true
+        quantity:
+          type: integer
+          enum: [1, 2, 5]
+        gift:
+          enum: [true, null]
+`
+
+	spec, err := v310.ParseYAML([]byte(doc))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	order := spec.Components.Schemas["Order"]
+	assert.True(t, order.Properties["id"].Value.ReadOnly)
+	assert.True(t, order.Properties["secret"].Value.WriteOnly)
+	assert.Equal(t, []interface{}{1, 2, 5}, order.Properties["quantity"].Value.Enum)
+	assert.Equal(t, []interface{}{true, nil}, order.Properties["gift"].Value.Enum)
+
+	// Keywords are written back with their OpenAPI names and types
+	buf, err := json.Marshal(spec)
+	assert.NoError(t, err)
+	assert.Contains(t, string(buf), `"readOnly":true`)
+	assert.Contains(t, string(buf), `"writeOnly":true`)
+	assert.Contains(t, string(buf), `"enum":[1,2,5]`)
+
+	fromJSON, err := v310.ParseJSON(buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	quantity := fromJSON.Components.Schemas["Order"].Properties["quantity"].Value
+	assert.Equal(t, []interface{}{1.0, 2.0, 5.0}, quantity.Enum)
+
+	// Values are compared by value rather than by type
+	tests := []struct {
+		val   string
+		valid bool
+	}{
+		{`{"quantity":2}`, true},
+		{`{"quantity":3}`, false},
+		{`{"gift":true}`, true},
+		{`{"gift":null}`, true},
+		{`{"gift":false}`, false},
+		{`{"gift":"true"}`, false},
+	}
+	for _, tc := range tests {
+		var val interface{}
+		assert.NoError(t, json.Unmarshal([]byte(tc.val), &val))
+
+		err := order.ValidateJSON(val, spec.Components)
+		if tc.valid {
+			assert.NoError(t, err, tc.val)
+		} else if se, ok := err.(*v310.SchemaError); assert.True(t, ok, tc.val) {
+			assert.Equal(t, "enum", se.Keyword, tc.val)
+		}
+	}
+}
+
+func TestLoadSpecRefs(t *testing.T) {
+	doc := `{
+		"openapi": "3.1.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {
+			"/nodes": {
+				"post": {
+					"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/$defs/Node"}}}}
+				}
+			}
+		}
+	}`
+
+	spec, err := v310.ParseJSON([]byte(doc))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Unsupported refs resolve to nil rather than panicking
+	ref := spec.Paths["/nodes"].Value.Post.RequestBody.Value.Content["application/json"].Schema
+	assert.Nil(t, ref.DeRef(&v310.Components{}))
+	assert.Nil(t, (&v310.Ref[v310.Schema]{Ref: "#/components/unknown/Node"}).DeRef(&v310.Components{}))
+	assert.EqualError(t, spec.CheckRefs(), "v310: unsupported $ref #/$defs/Node, only #/components/<type>/<name> refs are supported")
+
+	// Component refs are reported when there are no components to resolve them
+	ref.Ref = "#/components/schemas/Node"
+	assert.EqualError(t, spec.CheckRefs(), "v310: $ref #/components/schemas/Node does not resolve to a Schema component")
+
+	spec.Components = &v310.Components{Schemas: map[string]*v310.Schema{"Node": {Type: v310.ObjectSchemaType}}}
+	assert.NoError(t, spec.CheckRefs())
+	assert.Equal(t, spec.Components.Schemas["Node"], ref.DeRef(spec.Components))
+
+	// Escaped names are unescaped as JSON pointer tokens
+	spec.Components.Schemas["a/b"] = &v310.Schema{}
+	assert.Equal(t, spec.Components.Schemas["a/b"], (&v310.Ref[v310.Schema]{Ref: "#/components/schemas/a~1b"}).DeRef(spec.Components))
+}
+
+func TestLoadSpecSecurityOptOut(t *testing.T) {
+	doc := `openapi: 3.1.0
+info:
+    title: Test
+    version: 1.0.0
+paths:
+    /open:
+        get:
+            operationId: open
+            security: []
+            servers:
+                - url: https://example.com
+    /secure:
+        get:
+            operationId: secure
+security:
+    - api_key: []
+`
+
+	spec, err := v310.ParseYAML([]byte(doc))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotNil(t, spec.Paths["/open"].Value.Get.Security)
+	assert.Empty(t, spec.Paths["/open"].Value.Get.Security)
+	assert.Nil(t, spec.Paths["/secure"].Value.Get.Security)
+
+	// An empty list is kept, whereas a missing one is still omitted
+	out, err := yaml.Marshal(spec)
+	assert.NoError(t, err)
+	assert.Equal(t, doc, string(out))
+
+	buf, err := json.Marshal(spec)
+	assert.NoError(t, err)
+	fromJSON, err := v310.ParseJSON(buf)
+	if assert.NoError(t, err) {
+		assert.NotNil(t, fromJSON.Paths["/open"].Value.Get.Security)
+		assert.Nil(t, fromJSON.Paths["/secure"].Value.Get.Security)
+	}
+	assert.Contains(t, string(buf), `"security":[]`)
+}
+
+func TestLoadSpecRefSiblings(t *testing.T) {
+	doc := `openapi: 3.1.0
+info:
+    title: Test
+    version: 1.0.0
+paths:
+    /pets:
+        get:
+            operationId: pets
+            parameters:
+                - $ref: '#/components/parameters/limit'
+                  description: Page size
+            responses:
+                "200":
+                    $ref: '#/components/responses/pets'
+                    summary: Pets
+                    description: All pets
+components:
+    schemas:
+        Name:
+            type: string
+        Pet:
+            type: object
+            properties:
+                name:
+                    $ref: '#/components/schemas/Name'
+                    description: Pet name
+                    maxLength: 5
+    responses:
+        pets:
+            description: Pets
+            content:
+                application/json:
+                    schema:
+                        $ref: '#/components/schemas/Pet'
+    parameters:
+        limit:
+            name: limit
+            in: query
+            schema:
+                type: integer
+`
+
+	spec, err := v310.ParseYAML([]byte(doc))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, spec.CheckRefs())
+
+	op := spec.Paths["/pets"].Value.Get
+	assert.Equal(t, "Page size", op.Parameters[0].Description)
+	assert.Equal(t, "Pets", op.Responses["200"].Summary)
+	assert.Equal(t, "All pets", op.Responses["200"].Description)
+
+	// Schema keywords alongside $ref are kept on the schema, and apply in addition to the referenced schema
+	name := spec.Components.Schemas["Pet"].Properties["name"]
+	if assert.NotNil(t, name.Value) {
+		assert.Equal(t, "#/components/schemas/Name", name.Value.Ref)
+		assert.Equal(t, "Pet name", name.Value.Description)
+		assert.NoError(t, name.Value.Validate("Rex", spec.Components))
+		assert.Error(t, name.Value.Validate("Rexford", spec.Components))
+	}
+
+	out, err := yaml.Marshal(spec)
+	assert.NoError(t, err)
+	assert.Equal(t, doc, string(out))
+
+	buf, err := json.Marshal(spec)
+	assert.NoError(t, err)
+	fromJSON, err := v310.ParseJSON(buf)
+	if assert.NoError(t, err) {
+		out, err = yaml.Marshal(fromJSON)
+		assert.NoError(t, err)
+		assert.Equal(t, doc, string(out))
+	}
+
+	// Other objects only allow summary and description alongside $ref
+	_, err = v310.ParseYAML([]byte(`paths:
+    /pets:
+        get:
+            parameters:
+                - $ref: '#/components/parameters/limit'
+                  required: true
+`))
+	assert.EqualError(t, err, "v310: unsupported key required alongside $ref")
+	_, err = v310.ParseJSON([]byte(`{"paths":{"/pets":{"get":{"parameters":[{"$ref":"#/components/parameters/limit","required":true}]}}}}`))
+	assert.EqualError(t, err, "v310: unsupported key required alongside $ref")
+}
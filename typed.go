@@ -0,0 +1,101 @@
+package echopen
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Router is implemented by both APIWrapper and GroupWrapper, allowing typed handlers to be added to either.
+type Router interface {
+	Add(method string, path string, handler echo.HandlerFunc, config ...RouteConfigFunc) *RouteWrapper
+}
+
+// TypedHandlerFunc receives the bound and validated request, and returns the value to send as the response body.
+type TypedHandlerFunc[Req any, Resp any] func(c echo.Context, req *Req) (*Resp, error)
+
+// Empty can be used as the request or response type of a typed handler which has no request or response body.
+type Empty struct{}
+
+// AddT adds a route with a typed handler.
+// The request schema is derived from Req, which is bound as a JSON request body, or as query params for GET, HEAD, and DELETE routes.
+// The 200 response schema is derived from Resp, and the returned value is sent as JSON.
+// A Resp of Empty sends a 204 with no content, while a nil response for any other Resp is returned as ErrResponseInvalid.
+// The request relies on the default middleware to bind and validate it, and ErrContextValueMissing is returned if nothing was bound.
+func AddT[Req any, Resp any](r Router, method string, path string, handler TypedHandlerFunc[Req, Resp], config ...RouteConfigFunc) *RouteWrapper {
+	reqType := reflect.TypeOf((*Req)(nil)).Elem()
+	if reqType.Kind() != reflect.Struct {
+		panic(fmt.Errorf("echopen: struct expected for request type, received %s", reqType.Kind()))
+	}
+
+	key := "body"
+	typed := []RouteConfigFunc{}
+
+	noRequest := reqType == reflect.TypeOf(Empty{})
+	if !noRequest {
+		switch strings.ToUpper(method) {
+		case http.MethodGet, http.MethodHead, http.MethodDelete:
+			key = "query"
+			typed = append(typed, WithQueryStruct(*new(Req)))
+		default:
+			typed = append(typed, WithRequestBodyStruct(echo.MIMEApplicationJSON, "", *new(Req)))
+		}
+	}
+
+	noContent := reflect.TypeOf((*Resp)(nil)).Elem() == reflect.TypeOf(Empty{})
+	if noContent {
+		typed = append(typed, WithResponseDescription(fmt.Sprint(http.StatusNoContent), "No content"))
+	} else {
+		typed = append(typed, WithResponseType(fmt.Sprint(http.StatusOK), "Successful response", *new(Resp)))
+	}
+
+	// Explicit config is applied afterwards so it can override the inferred responses
+	return r.Add(method, path, func(c echo.Context) error {
+		req := new(Req)
+		if !noRequest {
+			var ok bool
+			if req, ok = c.Get(key).(*Req); !ok {
+				// Never call the handler with a request which has not been bound and validated
+				return fmt.Errorf("%w: %s", ErrContextValueMissing, key)
+			}
+		}
+
+		resp, err := handler(c, req)
+		if err != nil {
+			return err
+		} else if noContent {
+			return c.NoContent(http.StatusNoContent)
+		} else if resp == nil {
+			// Only the 200 response is declared
+			return fmt.Errorf("%w: nil response from typed handler", ErrResponseInvalid)
+		}
+		return c.JSON(http.StatusOK, resp)
+	}, append(typed, config...)...)
+}
+
+func DELETET[Req any, Resp any](r Router, path string, handler TypedHandlerFunc[Req, Resp], config ...RouteConfigFunc) *RouteWrapper {
+	return AddT(r, http.MethodDelete, path, handler, config...)
+}
+
+func GETT[Req any, Resp any](r Router, path string, handler TypedHandlerFunc[Req, Resp], config ...RouteConfigFunc) *RouteWrapper {
+	return AddT(r, http.MethodGet, path, handler, config...)
+}
+
+func HEADT[Req any, Resp any](r Router, path string, handler TypedHandlerFunc[Req, Resp], config ...RouteConfigFunc) *RouteWrapper {
+	return AddT(r, http.MethodHead, path, handler, config...)
+}
+
+func PATCHT[Req any, Resp any](r Router, path string, handler TypedHandlerFunc[Req, Resp], config ...RouteConfigFunc) *RouteWrapper {
+	return AddT(r, http.MethodPatch, path, handler, config...)
+}
+
+func POSTT[Req any, Resp any](r Router, path string, handler TypedHandlerFunc[Req, Resp], config ...RouteConfigFunc) *RouteWrapper {
+	return AddT(r, http.MethodPost, path, handler, config...)
+}
+
+func PUTT[Req any, Resp any](r Router, path string, handler TypedHandlerFunc[Req, Resp], config ...RouteConfigFunc) *RouteWrapper {
+	return AddT(r, http.MethodPut, path, handler, config...)
+}
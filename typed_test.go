@@ -0,0 +1,107 @@
+package echopen_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/richjyoung/echopen"
+	"github.com/stretchr/testify/assert"
+)
+
+type TypedPetRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type TypedPet struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type TypedPetQuery struct {
+	Limit int `query:"limit" validate:"max=10"`
+}
+
+func TestTypedHandlers(t *testing.T) {
+	api := echopen.New("Test", "1.0.0")
+
+	echopen.POSTT(api, "/pets", func(c echo.Context, req *TypedPetRequest) (*TypedPet, error) {
+		return &TypedPet{ID: 1, Name: req.Name}, nil
+	})
+
+	echopen.GETT(api.Group("/v1"), "/pets", func(c echo.Context, req *TypedPetQuery) (*[]TypedPet, error) {
+		pets := make([]TypedPet, req.Limit)
+		return &pets, nil
+	})
+
+	echopen.DELETET(api, "/pets/:id", func(c echo.Context, req *echopen.Empty) (*echopen.Empty, error) {
+		return nil, nil
+	})
+
+	post := api.Spec.Paths["/pets"].Value.Post
+	assert.Equal(t, "#/components/schemas/TypedPetRequest", post.RequestBody.Value.Content[echo.MIMEApplicationJSON].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/TypedPet", post.Responses["200"].Value.Content[echo.MIMEApplicationJSON].Schema.Ref)
+
+	get := api.Spec.Paths["/v1/pets"].Value.Get
+	assert.Len(t, get.Parameters, 1)
+	assert.Equal(t, "array", string(get.Responses["200"].Value.Content[echo.MIMEApplicationJSON].Schema.Value.Type))
+
+	del := api.Spec.Paths["/pets/{id}"].Value.Delete
+	assert.Nil(t, del.RequestBody)
+	assert.Contains(t, del.Responses, "204")
+
+	// Body bound and response serialised
+	req := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"name":"Rex"}`))
+	req.Header.Set("Content-Type", echo.MIMEApplicationJSON)
+	res := httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.JSONEq(t, `{"id":1,"name":"Rex"}`, res.Body.String())
+
+	// Body validated before the handler
+	req = httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", echo.MIMEApplicationJSON)
+	res = httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 400, res.Result().StatusCode)
+
+	// Query bound for GET
+	_, res = executeRequest(api, http.MethodGet, "/v1/pets?limit=2", nil)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.JSONEq(t, `[{"id":0,"name":""},{"id":0,"name":""}]`, res.Body.String())
+
+	_, res = executeRequest(api, http.MethodGet, "/v1/pets?limit=20", nil)
+	assert.Equal(t, 400, res.Result().StatusCode)
+
+	// No content
+	_, res = executeRequest(api, http.MethodDelete, "/pets/1", nil)
+	assert.Equal(t, 204, res.Result().StatusCode)
+}
+
+func TestTypedHandlersErrors(t *testing.T) {
+	called := false
+
+	api := echopen.New("Test", "1.0.0")
+	api.Config.DisableDefaultMiddleware = true
+	echopen.POSTT(api, "/pets", func(c echo.Context, req *TypedPetRequest) (*TypedPet, error) {
+		called = true
+		return &TypedPet{ID: 1, Name: req.Name}, nil
+	})
+	echopen.GETT(api, "/pets", func(c echo.Context, req *echopen.Empty) (*TypedPet, error) {
+		return nil, nil
+	})
+
+	// Handler not called without a bound request
+	req := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"name":"Rex"}`))
+	req.Header.Set("Content-Type", echo.MIMEApplicationJSON)
+	res := httptest.NewRecorder()
+	api.Engine.ServeHTTP(res, req)
+	assert.Equal(t, 500, res.Result().StatusCode)
+	assert.False(t, called)
+
+	// Nil responses are only allowed for Empty
+	_, res = executeRequest(api, http.MethodGet, "/pets", nil)
+	assert.Equal(t, 500, res.Result().StatusCode)
+}
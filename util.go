@@ -6,6 +6,7 @@ import (
 )
 
 var reParam = regexp.MustCompile(`\:(\w+)`)
+var reOpenAPIParam = regexp.MustCompile(`\{([^}/]+)\}`)
 
 func genOpID(method string, path string) string {
 	s := strings.ToLower(method)
@@ -28,4 +29,17 @@ func echoRouteToOpenAPI(path string) string {
 	return reParam.ReplaceAllString(path, "{$1}")
 }
 
+func openAPIRouteToEcho(path string) string {
+	return reOpenAPIParam.ReplaceAllString(path, ":$1")
+}
+
+// routeParams returns the names of the parameters in an echo format route path
+func routeParams(path string) map[string]bool {
+	params := map[string]bool{}
+	for _, m := range reParam.FindAllStringSubmatch(path, -1) {
+		params[m[1]] = true
+	}
+	return params
+}
+
 func PtrTo[T any](v T) *T { return &v }
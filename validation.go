@@ -0,0 +1,112 @@
+package echopen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
+)
+
+type ParameterErrorReason string
+
+const (
+	ParameterMissing ParameterErrorReason = "missing"
+	ParameterInvalid ParameterErrorReason = "invalid"
+)
+
+// ParameterError reports a path, header, or cookie parameter which was missing or could not be parsed.
+// Matches ErrRequiredParameterMissing with errors.Is
+// Rule and Param are set when an invalid parameter parsed correctly but failed a schema keyword (e.g. maximum).
+type ParameterError struct {
+	Name   string
+	In     v310.ParameterLocation
+	Reason ParameterErrorReason
+	Rule   string
+	Param  string
+}
+
+func (e *ParameterError) Error() string {
+	if e.Rule != "" {
+		return fmt.Sprintf("echopen: %s parameter %s failed validation rule %s", e.In, e.Name, e.Rule)
+	} else if e.Reason == ParameterInvalid {
+		return fmt.Sprintf("echopen: %s parameter %s has an invalid format", e.In, e.Name)
+	}
+	return fmt.Sprintf("echopen: required %s parameter %s missing", e.In, e.Name)
+}
+
+func (e *ParameterError) Is(target error) bool {
+	return target == ErrRequiredParameterMissing
+}
+
+// ValidationError records the location of a value which failed struct validation.
+// It unwraps to the underlying validator.ValidationErrors.
+type ValidationError struct {
+	In     string
+	Errors validator.ValidationErrors
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("echopen: %s validation failed: %s", e.In, e.Errors.Error())
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Errors
+}
+
+// validationError wraps validator.ValidationErrors with the location of the validated value, passing other errors through
+func validationError(in string, err error) error {
+	if ve, ok := err.(validator.ValidationErrors); ok {
+		return &ValidationError{In: in, Errors: ve}
+	}
+	return err
+}
+
+// SchemaValidationError records the location of a value which failed JSON schema validation.
+// It unwraps to the underlying *v310.SchemaError.
+type SchemaValidationError struct {
+	In  string
+	Err *v310.SchemaError
+}
+
+func (e *SchemaValidationError) Error() string {
+	return fmt.Sprintf("echopen: %s validation failed: %s", e.In, e.Err.Error())
+}
+
+func (e *SchemaValidationError) Unwrap() error {
+	return e.Err
+}
+
+// schemaValidationError wraps a *v310.SchemaError with the location of the validated value, passing other errors through
+func schemaValidationError(in string, err error) error {
+	if se, ok := err.(*v310.SchemaError); ok {
+		return &SchemaValidationError{In: in, Err: se}
+	}
+	return err
+}
+
+// newValidator creates a validator which reports field names from the given struct tag (e.g. json or query),
+// falling back to the Go field name where the tag is not present.
+func newValidator(nameTag string) *validator.Validate {
+	val := validator.New(validator.WithRequiredStructEnabled())
+	val.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.Split(f.Tag.Get(nameTag), ",")[0]
+		if name == "-" {
+			return ""
+		} else if name == "" {
+			return f.Name
+		}
+		return name
+	})
+	return val
+}
+
+// validationFieldName returns the path of a failed field relative to the validated struct
+func validationFieldName(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
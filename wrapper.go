@@ -1,6 +1,8 @@
 package echopen
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,6 +10,7 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"sync/atomic"
 
 	v310 "github.com/richjyoung/echopen/openapi/v3.1.0"
 
@@ -18,14 +21,23 @@ import (
 type Config struct {
 	BaseURL                  string
 	DisableDefaultMiddleware bool
+	JWT                      *JWTConfig
+	ValidateResponses        ResponseValidationMode
 }
 
 type APIWrapper struct {
+	// Spec may be changed directly, but SpecChanged must be called afterwards for served documents to pick up the changes
 	Spec   *v310.Specification
 	Engine *echo.Echo
 	Config *Config
 
-	schemaMap map[reflect.Type]string
+	schemaMap          map[reflect.Type]string
+	securityVerifiers  map[string]SecurityVerifierFunc
+	tokenIntrospectors map[string]TokenIntrospector
+	problemDetails     bool
+	frozen             atomic.Bool
+	specGeneration     atomic.Uint64
+	operations         map[string]*RouteWrapper
 }
 
 func New(title string, apiVersion string, config ...WrapperConfigFunc) *APIWrapper {
@@ -34,7 +46,9 @@ func New(title string, apiVersion string, config ...WrapperConfigFunc) *APIWrapp
 		Engine: echo.New(),
 		Config: &Config{},
 
-		schemaMap: map[reflect.Type]string{},
+		schemaMap:          map[reflect.Type]string{},
+		securityVerifiers:  map[string]SecurityVerifierFunc{},
+		tokenIntrospectors: map[string]TokenIntrospector{},
 	}
 
 	wrapper.Spec.Info.Title = title
@@ -65,50 +79,21 @@ func (w *APIWrapper) WriteYAMLSpec(path string) error {
 	return f.Close()
 }
 
+// ServeYAMLSpec serves the spec as YAML, including routes added afterwards.
+// The document is built on the first request and cached, then rebuilt as routes are added until it is frozen.
 func (w *APIWrapper) ServeYAMLSpec(path string, filters ...SpecFilterFunc) *echo.Route {
-	s := w.Spec
-
-	if len(filters) > 0 {
-		s = w.Spec.Copy()
-		for _, f := range filters {
-			s = f(s)
-		}
-	}
-
-	buf, err := yaml.Marshal(s)
-
-	var handler echo.HandlerFunc = func(c echo.Context) error {
-		if err != nil {
-			return err
-		}
-		return c.Blob(http.StatusOK, "application/yaml", buf)
-	}
+	doc := &specDocument{api: w, filters: filters, contentType: MIMEApplicationYAML, marshal: yaml.Marshal}
 
 	// Attach directly to the echo engine so the schema is not visible in the schema
-	return w.Engine.GET(path, handler)
+	return w.Engine.GET(path, doc.handler)
 }
 
+// ServeJSONSpec serves the spec as JSON, in the same way as ServeYAMLSpec
 func (w *APIWrapper) ServeJSONSpec(path string, filters ...SpecFilterFunc) *echo.Route {
-	s := w.Spec
-
-	if len(filters) > 0 {
-		s = w.Spec.Copy()
-		for _, f := range filters {
-			s = f(s)
-		}
-	}
-
-	buf, err := json.Marshal(s)
-
-	var handler echo.HandlerFunc = func(c echo.Context) error {
-		if err != nil {
-			return err
-		}
-		return c.Blob(http.StatusOK, "application/json", buf)
-	}
+	doc := &specDocument{api: w, filters: filters, contentType: echo.MIMEApplicationJSON, marshal: json.Marshal}
 
 	// Attach directly to the echo engine so the schema is not visible in the schema
-	return w.Engine.GET(path, handler)
+	return w.Engine.GET(path, doc.handler)
 }
 
 func (w *APIWrapper) ServeSwaggerUI(path string, schemaPath string, version string) *echo.Route {
@@ -174,8 +159,48 @@ func (w *APIWrapper) Start(addr string) error {
 	return w.Engine.Start(addr)
 }
 
+// StartMutualTLS starts an HTTPS server which requests client certificates signed by the CAs in clientCAFile.
+// Verified certificates satisfy mutualTLS security requirements.
+func (w *APIWrapper) StartMutualTLS(addr string, certFile string, keyFile string, clientCAFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+
+	buf, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(buf) {
+		return fmt.Errorf("echopen: no certificates found in %s", clientCAFile)
+	}
+
+	return w.Engine.StartServer(&http.Server{
+		Addr:      addr,
+		TLSConfig: NewMutualTLSConfig(pool, cert),
+	})
+}
+
+// NewMutualTLSConfig creates a TLS config which verifies client certificates against clientCAs when presented.
+// Certificates are not required at the TLS layer, so routes without a mutualTLS requirement remain reachable.
+func NewMutualTLSConfig(clientCAs *x509.CertPool, certs ...tls.Certificate) *tls.Config {
+	return &tls.Config{
+		Certificates: certs,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
 // Register a new route with the given method and path
 func (w *APIWrapper) Add(method string, path string, handler echo.HandlerFunc, config ...RouteConfigFunc) *RouteWrapper {
+	if w.Frozen() {
+		panic(fmt.Sprintf("echopen: cannot add %s %s after the spec is frozen", method, path))
+	}
+	defer w.SpecChanged()
+
 	// Construct a new operation for this path and method
 	op := &v310.Operation{}
 
@@ -218,6 +243,8 @@ func (w *APIWrapper) Add(method string, path string, handler echo.HandlerFunc, c
 	// Start populating return wrapper
 	wrapper := &RouteWrapper{
 		API:               w,
+		Method:            strings.ToUpper(method),
+		Path:              path,
 		Operation:         op,
 		PathItem:          pathItem,
 		Handler:           handler,
@@ -232,11 +259,20 @@ func (w *APIWrapper) Add(method string, path string, handler echo.HandlerFunc, c
 		wrapper = configFunc(wrapper)
 	}
 
+	// Check the types expected by the handler against those bound
+	wrapper.checkContextTypes()
+
+	// Document validation failures
+	wrapper.addProblemDetailsResponse()
+
 	// Add validation middleware to the start of the chain
 	middlewares := []echo.MiddlewareFunc{}
 	if !w.Config.DisableDefaultMiddleware {
 		middlewares = append(middlewares, wrapper.middleware())
 	}
+	if w.Config.ValidateResponses != ResponseValidationDisabled {
+		middlewares = append(middlewares, wrapper.responseValidationMiddleware())
+	}
 	middlewares = append(middlewares, wrapper.Middlewares...)
 
 	// Add the route in to the echo engine
@@ -250,6 +286,8 @@ func (w *APIWrapper) Add(method string, path string, handler echo.HandlerFunc, c
 
 // Create a new group with prefix and optional group-specific configuration
 func (w *APIWrapper) Group(prefix string, config ...GroupConfigFunc) *GroupWrapper {
+	defer w.SpecChanged()
+
 	wrapper := &GroupWrapper{
 		Prefix: prefix,
 		API:    w,
@@ -301,10 +339,16 @@ func (w *APIWrapper) TRACE(path string, handler echo.HandlerFunc, config ...Rout
 
 // Extend the default echo handler to cover errors defined by echopen
 func DefaultErrorHandler(err error, c echo.Context) {
-	if errors.Is(err, ErrSecurityRequirementsNotMet) {
+	if p, ok := problemFromError(err); ok {
+		WriteProblem(c, p)
+	} else if errors.Is(err, ErrSecurityRequirementsNotMet) || errors.Is(err, ErrSecurityCredentialsInvalid) {
 		c.JSON(http.StatusUnauthorized, map[string]interface{}{
 			"message": http.StatusText(http.StatusUnauthorized),
 		})
+	} else if errors.Is(err, ErrSecurityAccessDenied) || errors.Is(err, ErrInsufficientScope) {
+		c.JSON(http.StatusForbidden, map[string]interface{}{
+			"message": http.StatusText(http.StatusForbidden),
+		})
 	} else if errors.Is(err, ErrRequiredParameterMissing) {
 		c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"message": http.StatusText(http.StatusBadRequest),
@@ -313,6 +357,14 @@ func DefaultErrorHandler(err error, c echo.Context) {
 		c.JSON(http.StatusUnsupportedMediaType, map[string]interface{}{
 			"message": http.StatusText(http.StatusUnsupportedMediaType),
 		})
+	} else if errors.Is(err, ErrNotImplemented) {
+		c.JSON(http.StatusNotImplemented, map[string]interface{}{
+			"message": http.StatusText(http.StatusNotImplemented),
+		})
+	} else if errors.Is(err, ErrNotAcceptable) {
+		c.JSON(http.StatusNotAcceptable, map[string]interface{}{
+			"message": http.StatusText(http.StatusNotAcceptable),
+		})
 	} else if he, ok := err.(*echo.HTTPError); ok {
 		if c.Echo().Debug && he.Internal != nil {
 			c.JSON(he.Code, map[string]interface{}{
@@ -11,6 +11,7 @@ type WrapperConfigFunc func(*APIWrapper) *APIWrapper
 
 func (a *APIWrapper) SetSpecDescription(desc string) {
 	a.Spec.Info.Description = strings.TrimSpace(desc)
+	a.SpecChanged()
 }
 
 func WithSpecDescription(desc string) WrapperConfigFunc {
@@ -22,6 +23,7 @@ func WithSpecDescription(desc string) WrapperConfigFunc {
 
 func (a *APIWrapper) SetTermsOfService(tos string) {
 	a.Spec.Info.TermsOfService = tos
+	a.SpecChanged()
 }
 
 func WithSpecTermsOfService(tos string) WrapperConfigFunc {
@@ -33,6 +35,7 @@ func WithSpecTermsOfService(tos string) WrapperConfigFunc {
 
 func (a *APIWrapper) SetSpecLicense(l *v310.License) {
 	a.Spec.Info.License = l
+	a.SpecChanged()
 }
 
 func WithSpecLicense(l *v310.License) WrapperConfigFunc {
@@ -45,12 +48,39 @@ func WithSpecLicense(l *v310.License) WrapperConfigFunc {
 func WithSpecTag(t *v310.Tag) WrapperConfigFunc {
 	return func(a *APIWrapper) *APIWrapper {
 		a.Spec.AddTag(t)
+		a.SpecChanged()
+		return a
+	}
+}
+
+func WithSpecSecurityScheme(name string, s *v310.SecurityScheme) WrapperConfigFunc {
+	return func(a *APIWrapper) *APIWrapper {
+		a.Spec.GetComponents().AddSecurityScheme(name, s)
+		a.SpecChanged()
+		return a
+	}
+}
+
+// WithSpecSecurityRequirement adds a top level security requirement, which applies to all routes without their own requirements.
+// Use WithOptionalSecurity to opt individual routes out.
+func WithSpecSecurityRequirement(name string, scopes []string) WrapperConfigFunc {
+	return func(a *APIWrapper) *APIWrapper {
+		if a.Spec.GetComponents().GetSecurityScheme(name) == nil {
+			panic("echopen: security scheme not registered")
+		}
+
+		a.Spec.AddSecurityRequirement(&v310.SecurityRequirement{
+			name: scopes,
+		})
+		a.SpecChanged()
+
 		return a
 	}
 }
 
 func (a *APIWrapper) SetSpecContact(c *v310.Contact) {
 	a.Spec.Info.Contact = c
+	a.SpecChanged()
 }
 
 func WithSpecContact(c *v310.Contact) WrapperConfigFunc {
@@ -64,6 +94,7 @@ func WithSpecServer(s *v310.Server) WrapperConfigFunc {
 	return func(a *APIWrapper) *APIWrapper {
 		s.URL += a.Config.BaseURL
 		a.Spec.AddServer(s)
+		a.SpecChanged()
 		return a
 	}
 }
@@ -74,6 +105,7 @@ func (a *APIWrapper) SetErrorHandler(h echo.HTTPErrorHandler) {
 
 func (a *APIWrapper) SetSpecExternalDocs(d *v310.ExternalDocs) {
 	a.Spec.ExternalDocs = d
+	a.SpecChanged()
 }
 
 func WithSpecExternalDocs(d *v310.ExternalDocs) WrapperConfigFunc {
@@ -85,11 +117,64 @@ func WithSpecExternalDocs(d *v310.ExternalDocs) WrapperConfigFunc {
 
 func (a *APIWrapper) SetBaseURL(baseURL string) {
 	a.Config.BaseURL = baseURL
+	a.SpecChanged()
 }
 
 func WithBaseURL(baseURL string) WrapperConfigFunc {
 	return func(a *APIWrapper) *APIWrapper {
-		a.Config.BaseURL = baseURL
+		a.SetBaseURL(baseURL)
+		return a
+	}
+}
+
+// SetSecurityVerifier registers a function to check credentials supplied for the named security scheme.
+// The principal returned by the verifier is added to the context under security.<name>.principal
+func (a *APIWrapper) SetSecurityVerifier(name string, v SecurityVerifierFunc) {
+	a.securityVerifiers[name] = v
+}
+
+func WithSecurityVerifier(name string, v SecurityVerifierFunc) WrapperConfigFunc {
+	return func(a *APIWrapper) *APIWrapper {
+		a.SetSecurityVerifier(name, v)
+		return a
+	}
+}
+
+// SetTokenIntrospector registers an introspector for the named OAuth2 or OpenID Connect security scheme.
+// Requests are rejected with ErrInsufficientScope if the token does not grant all scopes of the requirement
+func (a *APIWrapper) SetTokenIntrospector(name string, i TokenIntrospector) {
+	a.tokenIntrospectors[name] = i
+}
+
+func WithTokenIntrospector(name string, i TokenIntrospector) WrapperConfigFunc {
+	return func(a *APIWrapper) *APIWrapper {
+		a.SetTokenIntrospector(name, i)
+		return a
+	}
+}
+
+// SetJWTConfig enables validation of JWT bearer tokens for HTTP bearer schemes with a BearerFormat of "JWT",
+// and for OAuth2/OpenID Connect schemes without a registered introspector.
+// Validated claims are added to the context under security.<name>.claims
+func (a *APIWrapper) SetJWTConfig(cfg *JWTConfig) {
+	a.Config.JWT = cfg
+}
+
+func WithJWTConfig(cfg *JWTConfig) WrapperConfigFunc {
+	return func(a *APIWrapper) *APIWrapper {
+		a.SetJWTConfig(cfg)
+		return a
+	}
+}
+
+// SetResponseValidation enables checking of handler responses against the spec for routes added afterwards.
+func (a *APIWrapper) SetResponseValidation(mode ResponseValidationMode) {
+	a.Config.ValidateResponses = mode
+}
+
+func WithResponseValidation(mode ResponseValidationMode) WrapperConfigFunc {
+	return func(a *APIWrapper) *APIWrapper {
+		a.SetResponseValidation(mode)
 		return a
 	}
 }